@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hack/internal/config"
+	kafka "hack/internal/lib/kafka"
+	"hack/internal/models"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// replayBatch is the number of entries written to Kafka at once.
+const replayBatch = 100
+
+// errStop ends a scan early.
+var errStop = errors.New("stop")
+
+func runDLQ(ctx context.Context, cfg *config.Config, command string, args []string, out io.Writer) error {
+	dlq := kafka.NewDLQ(cfg.Brokers, cfg.DLQTopic)
+	defer dlq.Close()
+
+	switch command {
+	case "list":
+		return dlqList(ctx, dlq, args, out)
+	case "show":
+		return dlqShow(ctx, dlq, args, out)
+	case "replay":
+		return dlqReplay(ctx, dlq, args, out)
+	default:
+		return fmt.Errorf("unknown dlq command %q", command)
+	}
+}
+
+// filterFlags registers the entry filter flags shared by list and replay.
+func filterFlags(fs *flag.FlagSet) func() (kafka.DLQFilter, error) {
+	errorText := fs.String("error", "", "only entries whose error contains the text")
+	topic := fs.String("topic", "", "only entries failed in the original topic")
+	since := fs.String("since", "", "only entries failed at or after the time")
+	until := fs.String("until", "", "only entries failed before the time")
+
+	return func() (kafka.DLQFilter, error) {
+		filter := kafka.DLQFilter{ErrorContains: *errorText, Topic: *topic}
+		var err error
+		if filter.Since, err = parseTimeFlag(*since, time.Now()); err != nil {
+			return filter, fmt.Errorf("-since: %w", err)
+		}
+		if filter.Until, err = parseTimeFlag(*until, time.Now()); err != nil {
+			return filter, fmt.Errorf("-until: %w", err)
+		}
+		return filter, nil
+	}
+}
+
+// parseTimeFlag accepts RFC 3339, a date or a duration back from now.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return models.ParseTime(value)
+}
+
+func dlqList(ctx context.Context, dlq *kafka.DLQ, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("dlq list", flag.ContinueOnError)
+	filter := filterFlags(fs)
+	limit := fs.Int("limit", 0, "print at most n entries, 0 for all")
+	asJSON := fs.Bool("json", false, "print entries as JSON lines")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if !*asJSON {
+		fmt.Fprintln(tw, "PARTITION\tOFFSET\tFAILED AT\tTOPIC\tKEY\tERROR")
+	}
+	enc := json.NewEncoder(out)
+
+	printed := 0
+	err = dlq.Scan(ctx, func(e kafka.DLQEntry) error {
+		if !f.Match(e) {
+			return nil
+		}
+		if *asJSON {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		} else {
+			printEntryRow(tw, e)
+		}
+		printed++
+		if *limit > 0 && printed == *limit {
+			return errStop
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		return err
+	}
+
+	if !*asJSON {
+		tw.Flush()
+		fmt.Fprintf(out, "%d entries\n", printed)
+	}
+	return nil
+}
+
+func dlqShow(ctx context.Context, dlq *kafka.DLQ, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("dlq show", flag.ContinueOnError)
+	partition := fs.Int("partition", 0, "DLQ partition of the entry")
+	offset := fs.Int64("offset", -1, "DLQ offset of the entry")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *offset < 0 {
+		return errors.New("-offset is required")
+	}
+
+	entry, err := findEntry(ctx, dlq, *partition, *offset)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(data))
+	return nil
+}
+
+func dlqReplay(ctx context.Context, dlq *kafka.DLQ, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("dlq replay", flag.ContinueOnError)
+	filter := filterFlags(fs)
+	partition := fs.Int("partition", 0, "with -offset: DLQ partition of the single entry to replay")
+	offset := fs.Int64("offset", -1, "replay only the entry at this DLQ offset")
+	dryRun := fs.Bool("dry-run", false, "only report what would be replayed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+
+	var entries []kafka.DLQEntry
+	if *offset >= 0 {
+		entry, err := findEntry(ctx, dlq, *partition, *offset)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	} else {
+		err := dlq.Scan(ctx, func(e kafka.DLQEntry) error {
+			if f.Match(e) {
+				entries = append(entries, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var replayable, skipped []kafka.DLQEntry
+	for _, e := range entries {
+		if e.DecodeErr != "" || e.Message.OriginalTopic == "" {
+			skipped = append(skipped, e)
+			continue
+		}
+		replayable = append(replayable, e)
+	}
+
+	replayed := 0
+	if !*dryRun {
+		for start := 0; start < len(replayable); start += replayBatch {
+			end := min(start+replayBatch, len(replayable))
+			if err := dlq.Replay(ctx, replayable[start:end]...); err != nil {
+				printReplayReport(out, replayable[:replayed], skipped, false)
+				return fmt.Errorf("replayed %d of %d entries: %w", replayed, len(replayable), err)
+			}
+			replayed = end
+		}
+	}
+
+	printReplayReport(out, replayable, skipped, *dryRun)
+	return nil
+}
+
+// findEntry scans the DLQ for the entry at the given position.
+func findEntry(ctx context.Context, dlq *kafka.DLQ, partition int, offset int64) (kafka.DLQEntry, error) {
+	var found *kafka.DLQEntry
+	err := dlq.Scan(ctx, func(e kafka.DLQEntry) error {
+		if e.Partition == partition && e.Offset == offset {
+			found = &e
+			return errStop
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		return kafka.DLQEntry{}, err
+	}
+	if found == nil {
+		return kafka.DLQEntry{}, fmt.Errorf("no entry at partition %d offset %d", partition, offset)
+	}
+	return *found, nil
+}
+
+func printReplayReport(out io.Writer, replayed, skipped []kafka.DLQEntry, dryRun bool) {
+	verb := "replayed"
+	if dryRun {
+		verb = "would replay"
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARTITION\tOFFSET\tFAILED AT\tTOPIC\tKEY\tERROR")
+	for _, e := range replayed {
+		printEntryRow(tw, e)
+	}
+	tw.Flush()
+	fmt.Fprintf(out, "%s %d entries\n", verb, len(replayed))
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(out, "skipped %d entries without an original topic:\n", len(skipped))
+		for _, e := range skipped {
+			reason := e.DecodeErr
+			if reason == "" {
+				reason = "original_topic is empty"
+			}
+			fmt.Fprintf(out, "  partition %d offset %d: %s\n", e.Partition, e.Offset, reason)
+		}
+	}
+}
+
+func printEntryRow(w io.Writer, e kafka.DLQEntry) {
+	errText := e.Message.Error
+	if e.DecodeErr != "" {
+		errText = "undecodable: " + e.DecodeErr
+	}
+	fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n",
+		e.Partition,
+		e.Offset,
+		e.Message.FailedAt.Format(time.RFC3339),
+		e.Message.OriginalTopic,
+		e.Message.OriginalKey,
+		strings.ReplaceAll(errText, "\n", " "),
+	)
+}
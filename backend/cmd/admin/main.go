@@ -0,0 +1,45 @@
+// Command admin contains maintenance tools for operators.
+//
+// Usage:
+//
+//	admin dlq list   [-error text] [-topic name] [-since time] [-until time] [-limit n] [-json]
+//	admin dlq show   -offset n [-partition n]
+//	admin dlq replay [-error text] [-topic name] [-since time] [-until time] [-partition n -offset n] [-dry-run]
+//
+// Times are RFC 3339, YYYY-MM-DD or a duration back from now, e.g. 24h.
+package main
+
+import (
+	"context"
+	"fmt"
+	"hack/internal/config"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `usage: admin <command> [arguments]
+
+commands:
+  dlq list     list dead letter queue entries
+  dlq show     print one entry with its original message
+  dlq replay   send entries back to their original topics
+
+run "admin dlq <command> -h" for the flags of a command`
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "dlq" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoad()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := runDLQ(ctx, cfg, os.Args[2], os.Args[3:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		os.Exit(1)
+	}
+}
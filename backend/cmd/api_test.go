@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hack/internal/config"
+	resp "hack/internal/lib/api/response"
+	"hack/internal/lib/health"
+	"hack/internal/lib/llm"
+	"hack/internal/models"
+	usecase "hack/internal/usecase"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// testAPI is the HTTP API running on in-memory dependencies.
+type testAPI struct {
+	t    *testing.T
+	srv  *httptest.Server
+	deps dependencies
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		Storage:  config.StorageMemory,
+		Auth:     config.Auth{SessionTTL: time.Hour, CookieName: "session_id"},
+		Analitic: config.Analitic{ResolutionSLA: 72 * time.Hour},
+	}
+	deps := newMemoryDependencies(log, cfg)
+
+	orderUseCase := usecase.NewStatementUseCase(deps.repo, deps.cache, deps.producer, llm.NewStub(), nil)
+	authUseCase := usecase.NewAuthUseCase(deps.repo, cfg.SessionTTL)
+	err := authUseCase.EnsureAdmin(context.Background(), models.Credentials{Username: "admin", Password: "secret-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- deps.submissions.Start(ctx, storeSubmission(log, orderUseCase)) }()
+
+	noMetrics := func(next http.Handler) http.Handler { return next }
+	srv := httptest.NewServer(newRouter(log, cfg, orderUseCase, authUseCase, deps.health, noMetrics))
+
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("consumer: %v", err)
+		}
+		deps.close(log)
+	})
+
+	return &testAPI{t: t, srv: srv, deps: deps}
+}
+
+// client returns a client keeping the session cookie.
+func (a *testAPI) client() *http.Client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return &http.Client{Jar: jar}
+}
+
+// do sends the request with body encoded as JSON and decodes the response into out, if it is not nil.
+func (a *testAPI) do(client *http.Client, method, path string, header http.Header, body, out any) *http.Response {
+	a.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.srv.URL+path, reader)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			a.t.Fatalf("%s %s: decode %q: %v", method, path, data, err)
+		}
+	}
+	return res
+}
+
+// waitStored waits until the submitted statements are stored.
+func (a *testAPI) waitStored() {
+	a.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.deps.submissions.WaitIdle(ctx); err != nil {
+		a.t.Fatalf("statements are not stored: %v", err)
+	}
+	if failed := a.deps.submissions.Failed(); len(failed) > 0 {
+		a.t.Fatalf("failed to store submission: %v", failed[0].Err)
+	}
+}
+
+func TestAPI_StatementLifecycle(t *testing.T) {
+	api := newTestAPI(t)
+	guest := api.client()
+	admin := api.client()
+
+	statement := models.Statement{
+		Source:      "portal",
+		District:    "Центральный",
+		Category:    "Мусор",
+		Subcategory: "Переполненные контейнеры",
+		Status:      models.StatusNew,
+		Description: "Контейнеры во дворе не вывозят неделю",
+	}
+
+	var accepted struct {
+		TrackingID string `json:"tracking_id"`
+	}
+	res := api.do(guest, http.MethodPost, "/api/statement", nil, []models.Statement{statement}, &accepted)
+	if res.StatusCode != http.StatusAccepted || accepted.TrackingID == "" {
+		t.Fatalf("POST /api/statement = %d, tracking id %q", res.StatusCode, accepted.TrackingID)
+	}
+	api.waitStored()
+
+	var ready health.Report
+	if res := api.do(guest, http.MethodGet, "/readyz", nil, nil, &ready); res.StatusCode != http.StatusOK || ready.Status != health.StatusOK {
+		t.Errorf("GET /readyz = %d %+v, want ok", res.StatusCode, ready)
+	}
+
+	if res := api.do(guest, http.MethodGet, "/api/statement", nil, nil, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("guest GET /api/statement = %d, want 401", res.StatusCode)
+	}
+
+	res = api.do(admin, http.MethodPost, "/api/auth/login", nil, models.Credentials{Username: "admin", Password: "secret-password"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("login = %d", res.StatusCode)
+	}
+
+	var pending models.StatementPage
+	api.do(admin, http.MethodGet, "/api/statement", nil, nil, &pending)
+	if pending.Total != 1 || len(pending.Items) != 1 || !pending.Items[0].AdminStatus {
+		t.Fatalf("moderation queue = %+v, want the guest statement once", pending)
+	}
+	stored := pending.Items[0]
+	path := fmt.Sprintf("/api/statement/%d", stored.StatementUID)
+
+	approved := stored
+	approved.AdminStatus = false
+	if res := api.do(admin, http.MethodPatch, path, nil, []models.Statement{approved}, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH %s = %d", path, res.StatusCode)
+	}
+
+	change := models.StatusChange{Status: models.StatusInWork, Reason: "передано подрядчику"}
+	if res := api.do(admin, http.MethodPost, path+"/status", nil, change, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s/status = %d", path, res.StatusCode)
+	}
+
+	var got models.Statement
+	api.do(guest, http.MethodGet, path, nil, nil, &got)
+	if got.Status != models.StatusInWork || got.AdminStatus {
+		t.Errorf("GET %s = %+v, want approved and in work", path, got)
+	}
+
+	if res := api.do(guest, http.MethodGet, path+"/transitions", nil, nil, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("guest GET %s/transitions = %d, want 401", path, res.StatusCode)
+	}
+	var transitions []models.StatusTransition
+	api.do(admin, http.MethodGet, path+"/transitions", nil, nil, &transitions)
+	if len(transitions) != 1 || transitions[0].To != models.StatusInWork {
+		t.Errorf("transitions = %+v, want the change to in work", transitions)
+	}
+	if res := api.do(admin, http.MethodGet, "/api/statement/999/transitions", nil, nil, nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("GET transitions of an unknown statement = %d, want 404", res.StatusCode)
+	}
+
+	var history []models.StatementEvent
+	api.do(admin, http.MethodGet, path+"/history", nil, nil, &history)
+	wantTypes := []models.EventType{models.EventCreated, models.EventModerated, models.EventStatusChanged}
+	if len(history) != len(wantTypes) {
+		t.Fatalf("history = %+v, want %v", history, wantTypes)
+	}
+	for i, e := range history {
+		if e.Type != wantTypes[i] {
+			t.Errorf("history[%d].type = %q, want %q", i, e.Type, wantTypes[i])
+		}
+	}
+
+	if res := api.do(admin, http.MethodGet, "/api/statement/999/history", nil, nil, nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("GET history of an unknown statement = %d, want 404", res.StatusCode)
+	}
+
+	var categories map[string]int
+	api.do(guest, http.MethodGet, "/api/analitic/categories", nil, nil, &categories)
+	if categories["Мусор"] != 1 {
+		t.Errorf("categories = %v, want one approved statement", categories)
+	}
+
+	var found models.SearchResult
+	api.do(admin, http.MethodGet, "/api/statement/search?q=контейнер", nil, nil, &found)
+	if found.Total != 1 || found.Items[0].Statement.StatementUID != stored.StatementUID {
+		t.Errorf("search = %+v, want the statement", found)
+	}
+
+	if res := api.do(admin, http.MethodDelete, "/api/statement/abc", nil, nil, nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("DELETE with a bad id = %d, want 400", res.StatusCode)
+	}
+	if res := api.do(admin, http.MethodDelete, path, nil, nil, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE %s = %d", path, res.StatusCode)
+	}
+	if res := api.do(admin, http.MethodDelete, path, nil, nil, nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("second DELETE %s = %d, want 404", path, res.StatusCode)
+	}
+	var deleted resp.Response
+	api.do(guest, http.MethodGet, path, nil, nil, &deleted)
+	if deleted.Error == "" {
+		t.Errorf("GET %s after delete = %+v, want an error", path, deleted)
+	}
+}
+
+func TestAPI_IdempotencyKey(t *testing.T) {
+	api := newTestAPI(t)
+	guest := api.client()
+	admin := api.client()
+
+	statement := models.Statement{
+		Source:      "portal",
+		District:    "Центральный",
+		Category:    "Мусор",
+		Subcategory: "Переполненные контейнеры",
+		Status:      models.StatusNew,
+		Description: "Контейнеры во дворе не вывозят неделю",
+	}
+	key := http.Header{"Idempotency-Key": {"retry-1"}}
+	post := func(client *http.Client) (string, bool) {
+		t.Helper()
+		var accepted struct {
+			TrackingID string `json:"tracking_id"`
+		}
+		res := api.do(client, http.MethodPost, "/api/statement", key, []models.Statement{statement}, &accepted)
+		if res.StatusCode != http.StatusAccepted {
+			t.Fatalf("POST /api/statement = %d", res.StatusCode)
+		}
+		return accepted.TrackingID, res.Header.Get("Idempotent-Replayed") == "true"
+	}
+
+	res := api.do(admin, http.MethodPost, "/api/auth/login", nil, models.Credentials{Username: "admin", Password: "secret-password"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("login = %d", res.StatusCode)
+	}
+	first, _ := post(admin)
+	if replay, replayed := post(admin); replay != first || !replayed {
+		t.Errorf("replayed POST = %q, replayed %v, want %q, true", replay, replayed, first)
+	}
+
+	// Guests share no identity, the key of one guest must not return the submission of another.
+	guestFirst, _ := post(guest)
+	if second, replayed := post(guest); second == guestFirst || replayed {
+		t.Errorf("guest POST with the same key = %q, replayed %v, want a new submission", second, replayed)
+	}
+	api.waitStored()
+}
+
+func TestAPI_Analitics(t *testing.T) {
+	api := newTestAPI(t)
+	client := api.client()
+
+	created := time.Date(2023, 12, 9, 10, 0, 0, 0, models.CityLocation)
+	var statements []models.Statement
+	for i, district := range []string{"Центральный", "Центральный", "Невский"} {
+		statements = append(statements, models.Statement{
+			Source:      "portal",
+			District:    district,
+			Category:    []string{"Мусор", "Дороги", "Мусор"}[i],
+			Subcategory: "Прочее",
+			CreatedAt:   created.AddDate(0, 0, i),
+			Status:      models.StatusNew,
+			AdminStatus: false,
+			Description: "Описание заявки для аналитики",
+		})
+	}
+
+	// Statements of guests go to moderation, so the admin submits already approved ones.
+	res := api.do(client, http.MethodPost, "/api/auth/login", nil, models.Credentials{Username: "admin", Password: "secret-password"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("login = %d", res.StatusCode)
+	}
+	if res := api.do(client, http.MethodPost, "/api/statement", nil, statements, nil); res.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /api/statement = %d", res.StatusCode)
+	}
+	api.waitStored()
+
+	categoryTests := []struct {
+		path string
+		want map[string]int
+	}{
+		{"/api/analitic/categories", map[string]int{"Мусор": 2, "Дороги": 1}},
+		{"/api/analitic/categories?district=Невский", map[string]int{"Мусор": 1}},
+		{"/api/analitic/categories?district=Невский,Центральный&status=Новое&source=portal", map[string]int{"Мусор": 2, "Дороги": 1}},
+		{"/api/analitic/categories?district=Центральный&from=2023-12-10&to=2023-12-10", map[string]int{"Дороги": 1}},
+		{"/api/analitic/categories?status=Решено", map[string]int{}},
+		// The legacy path: a district in the URL, "1" for all districts.
+		{"/api/analitic/categories/" + url.PathEscape("Невский"), map[string]int{"Мусор": 1}},
+		{"/api/analitic/categories/1", map[string]int{"Мусор": 2, "Дороги": 1}},
+		{"/api/analitic/categories/" + url.PathEscape("Центральный") + "?to=2023-12-09", map[string]int{"Мусор": 1}},
+	}
+	for _, tt := range categoryTests {
+		var got map[string]int
+		if res := api.do(client, http.MethodGet, tt.path, nil, nil, &got); res.StatusCode != http.StatusOK || !maps.Equal(got, tt.want) {
+			t.Errorf("GET %s = %d %v, want %v", tt.path, res.StatusCode, got, tt.want)
+		}
+	}
+	if res := api.do(client, http.MethodGet, "/api/analitic/categories?from=09.12.2023", nil, nil, nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("categories with a malformed date = %d, want 400", res.StatusCode)
+	}
+
+	var tab models.CrossTab
+	api.do(client, http.MethodGet, "/api/analitic/crosstab", nil, nil, &tab)
+	if tab.Total != 3 || len(tab.Districts) != 2 || len(tab.Categories) != 2 {
+		t.Errorf("crosstab = %+v, want 3 statements in 2 districts and 2 categories", tab)
+	}
+
+	var series models.PeriodSeries
+	api.do(client, http.MethodGet, "/api/analitic/period?bucket=day&from=2023-12-09&to=2023-12-11", nil, nil, &series)
+	if series.Total != 3 || len(series.Points) != 3 {
+		t.Errorf("period = %+v, want 3 days with one statement each", series)
+	}
+
+	var resolution models.ResolutionReport
+	api.do(client, http.MethodGet, "/api/analitic/resolution?group_by=district", nil, nil, &resolution)
+	if resolution.Total.Open != 3 || len(resolution.Groups) != 2 {
+		t.Errorf("resolution = %+v, want 3 open statements in 2 districts", resolution)
+	}
+}
+
+func TestAPI_Auth(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.client()
+	moderator := api.client()
+
+	if res := api.do(admin, http.MethodGet, "/api/auth/me", nil, nil, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("anonymous GET /api/auth/me = %d, want 401", res.StatusCode)
+	}
+	wrong := models.Credentials{Username: "admin", Password: "wrong-password"}
+	if res := api.do(admin, http.MethodPost, "/api/auth/login", nil, wrong, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("login with a wrong password = %d, want 401", res.StatusCode)
+	}
+
+	res := api.do(admin, http.MethodPost, "/api/auth/login", nil, models.Credentials{Username: "admin", Password: "secret-password"}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("login = %d", res.StatusCode)
+	}
+	var me models.User
+	if res := api.do(admin, http.MethodGet, "/api/auth/me", nil, nil, &me); res.StatusCode != http.StatusOK || me.Username != "admin" || me.Role != models.RoleAdmin {
+		t.Errorf("GET /api/auth/me = %d %+v, want admin", res.StatusCode, me)
+	}
+
+	newUser := models.NewUser{Credentials: models.Credentials{Username: "moderator", Password: "moderator-password"}, Role: models.RoleModerator}
+	if res := api.do(admin, http.MethodPost, "/api/admin/users", nil, newUser, nil); res.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /api/admin/users = %d", res.StatusCode)
+	}
+	if res := api.do(moderator, http.MethodPost, "/api/auth/login", nil, newUser.Credentials, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("moderator login = %d", res.StatusCode)
+	}
+	if res := api.do(moderator, http.MethodGet, "/api/admin/users", nil, nil, nil); res.StatusCode != http.StatusForbidden {
+		t.Errorf("moderator GET /api/admin/users = %d, want 403", res.StatusCode)
+	}
+	if res := api.do(moderator, http.MethodGet, "/api/statement", nil, nil, nil); res.StatusCode != http.StatusOK {
+		t.Errorf("moderator GET /api/statement = %d, want 200", res.StatusCode)
+	}
+
+	u, err := url.Parse(api.srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := admin.Jar.Cookies(u)
+	if res := api.do(admin, http.MethodPost, "/api/auth/logout", nil, nil, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("logout = %d", res.StatusCode)
+	}
+	if res := api.do(admin, http.MethodGet, "/api/auth/me", nil, nil, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/auth/me after logout = %d, want 401", res.StatusCode)
+	}
+	stale := http.Header{"Cookie": {session[0].String()}}
+	if res := api.do(api.client(), http.MethodGet, "/api/admin/users", stale, nil, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/admin/users with a closed session = %d, want 401", res.StatusCode)
+	}
+}
@@ -2,16 +2,13 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"hack/internal/app"
 	"hack/internal/config"
-	"hack/internal/delivery/handlers"
-	mwLogger "hack/internal/delivery/middleware/logger"
 	kafka "hack/internal/lib/kafka"
 	"hack/internal/lib/logger/sl"
 	"hack/internal/lib/logger/slogpretty"
-	"hack/internal/repository/postgres"
-	"hack/internal/repository/redis"
+	"hack/internal/models"
 	usecase "hack/internal/usecase"
 	"log/slog"
 	"net/http"
@@ -21,10 +18,6 @@ import (
 	"time"
 
 	chiprom "github.com/766b/chi-prometheus"
-	"github.com/go-chi/chi/middleware"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/cors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -34,57 +27,75 @@ func main() {
 	log := slogpretty.SetupLogger(cfg.Env)
 	log.Info("starting server", slog.String("env", cfg.Env))
 
-	db, err := sql.Open("pgx", cfg.DSN())
+	deps := mustLoadDependencies(log, cfg)
+
+	llmClient, err := app.NewLLMClient(cfg.LLM)
 	if err != nil {
-		log.Error("failed to init storage", sl.Err(err))
+		log.Error("failed to init llm client", sl.Err(err))
 		os.Exit(1)
 	}
+	log.Info("llm client initialized", slog.String("provider", cfg.LLM.Provider), slog.String("model", cfg.LLM.Model))
 
-	statementRepo := postgres.MustLoad(log, db, cfg.MigrationsPath)
-
-	redisConn := redis.MustLoad(log, cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.Password, cfg.DB)
-	kafkaProducer := kafka.MustProducer(log, cfg.Brokers, cfg.Topic)
+	// Statements are classified when they are stored: by cmd/worker, or by the API itself in the memory mode.
+	var categoryClassifier *usecase.CategoryClassifier
+	if deps.submissions != nil {
+		categoryClassifier = app.NewClassifier(log, cfg.Classifier, deps.repo, llmClient)
+	}
+	var classifier usecase.Classifier
+	if categoryClassifier != nil {
+		classifier = categoryClassifier
+	}
 
-	orderUseCase := usecase.NewStatementUseCase(statementRepo, redisConn, kafkaProducer)
+	orderUseCase := usecase.NewStatementUseCase(deps.repo, deps.cache, deps.producer, llmClient, classifier)
+	authUseCase := usecase.NewAuthUseCase(deps.repo, cfg.SessionTTL)
+
+	if cfg.AdminUsername != "" && cfg.AdminPassword != "" {
+		err := authUseCase.EnsureAdmin(context.Background(), models.Credentials{
+			Username: cfg.AdminUsername,
+			Password: cfg.AdminPassword,
+		})
+		if err != nil {
+			log.Error("failed to create bootstrap user", sl.Err(err))
+			os.Exit(1)
+		}
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	g, ctx := errgroup.WithContext(ctx)
 
-	router := chi.NewRouter()
-
-	router.Use(middleware.RequestID)
-	router.Use(middleware.Logger)
-	router.Use(mwLogger.New(log))
-	router.Use(middleware.Recoverer)
-
-	// позже нужно добавить метрики
-	router.Use(chiprom.NewMiddleware("my-service"))
-	// router.Use(middleware.URLFormat)
+	g.Go(func() error {
+		return deps.health.Run(ctx)
+	})
 
-	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173", "http://0.0.0.0:*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	if categoryClassifier != nil {
+		g.Go(func() error {
+			return categoryClassifier.Run(ctx)
+		})
+	}
 
-	router.Handle("/metrics", promhttp.Handler())
+	eventsRelay := usecase.NewOutboxRelay(log, deps.repo, models.OutboxEvents, deps.events, cfg.PollInterval, cfg.BatchSize)
+	g.Go(func() error {
+		return eventsRelay.Run(ctx)
+	})
+	submissionsRelay := usecase.NewOutboxRelay(log, deps.repo, models.OutboxSubmissions, deps.producer, cfg.PollInterval, cfg.BatchSize)
+	g.Go(func() error {
+		return submissionsRelay.Run(ctx)
+	})
 
-	router.Post("/api/statement", handlers.NewStatement(log, orderUseCase))
-	router.Get("/api/statement", handlers.GetAllNewStatements(log, orderUseCase))
+	keyPurger := usecase.NewIdempotencyKeyPurger(log, deps.repo, cfg.Idempotency.PurgeInterval)
+	g.Go(func() error {
+		return keyPurger.Run(ctx)
+	})
 
-	router.Patch("/api/statement/{id}", handlers.UpdateStatement(log, orderUseCase))
-	router.Get("/api/statement/{id}", handlers.GetStatement(log, orderUseCase))
-	router.Delete("/api/statement/{id}", handlers.DeleteStatement(log, orderUseCase))
+	if deps.submissions != nil {
+		g.Go(func() error {
+			return deps.submissions.Start(ctx, storeSubmission(log, orderUseCase))
+		})
+	}
 
-	router.Get("/api/analitic/categories/{district}", handlers.GetCategoriesAnalitic(log, orderUseCase))
-	router.Get("/api/analitic/period", handlers.GetPeriodAnalitic(log, orderUseCase))
-	router.Get("/api/analitic/district", handlers.GetDistrictAnalitic(log, orderUseCase))
-	router.Get("/api/analitic/recs", handlers.GetRecomendations(log, orderUseCase))
+	router := newRouter(log, cfg, orderUseCase, authUseCase, deps.health, chiprom.NewMiddleware("my-service"))
 
 	srv := &http.Server{
 		Addr:         cfg.Address,
@@ -118,15 +129,18 @@ func main() {
 	}
 
 	log.Info("closing resources...")
-	if err := db.Close(); err != nil {
-		log.Error("error closing database", sl.Err(err))
-	}
-	if err := redisConn.Close(); err != nil {
-		log.Error("error closing redis", sl.Err(err))
-	}
-	if err := kafkaProducer.Close(); err != nil {
-		log.Error("error closing kafka producer", sl.Err(err))
-	}
+	deps.close(log)
 
 	log.Info("server stopped gracefully")
 }
+
+// storeSubmission stores submissions consumed from the in-memory broker, like cmd/worker does from Kafka.
+func storeSubmission(log *slog.Logger, orderUseCase *usecase.StatementUseCase) kafka.MessageHandler {
+	return func(ctx context.Context, value []byte) error {
+		if err := orderUseCase.StoreSubmission(ctx, value); err != nil {
+			log.Error("failed to store submission", sl.Err(err))
+			return err
+		}
+		return nil
+	}
+}
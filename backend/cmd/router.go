@@ -0,0 +1,100 @@
+package main
+
+import (
+	"hack/internal/config"
+	"hack/internal/delivery/handlers"
+	mwAuth "hack/internal/delivery/middleware/auth"
+	mwLogger "hack/internal/delivery/middleware/logger"
+	"hack/internal/lib/health"
+	usecase "hack/internal/usecase"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newRouter builds the HTTP API. metrics is the Prometheus middleware, it is passed in
+// because it registers its collectors globally and can only be created once per process.
+func newRouter(log *slog.Logger, cfg *config.Config, orderUseCase *usecase.StatementUseCase, authUseCase *usecase.AuthUseCase, checker *health.Checker, metrics func(http.Handler) http.Handler) http.Handler {
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Logger)
+	router.Use(mwLogger.New(log))
+	router.Use(middleware.Recoverer)
+
+	router.Use(metrics)
+	// router.Use(middleware.URLFormat)
+
+	router.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   []string{"http://localhost:5173", "http://0.0.0.0:*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "Idempotent-Replayed"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// chi panics on middlewares added after a route, so the session middleware goes before /metrics.
+	sessionCookie := handlers.SessionCookie{Name: cfg.CookieName, Secure: cfg.CookieSecure}
+	router.Use(mwAuth.New(log, authUseCase, cfg.CookieName))
+
+	router.Handle("/metrics", promhttp.Handler())
+	router.Get("/healthz", handlers.Live())
+	router.Get("/readyz", handlers.Ready(checker))
+
+	router.Post("/api/auth/login", handlers.Login(log, authUseCase, sessionCookie))
+	router.Post("/api/auth/logout", handlers.Logout(log, authUseCase, sessionCookie))
+
+	router.Group(func(r chi.Router) {
+		r.Use(mwAuth.RequireUser)
+		r.Get("/api/auth/me", handlers.Me(log))
+	})
+
+	router.With(mwAuth.Require(usecase.PermStatementCreate)).
+		Post("/api/statement", handlers.NewStatement(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementModerate, usecase.PermStatementStatus)).
+		Get("/api/statement", handlers.ListStatements(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementModerate, usecase.PermStatementStatus)).
+		Get("/api/statement/search", handlers.SearchStatements(log, orderUseCase))
+
+	router.With(mwAuth.Require(usecase.PermStatementModerate, usecase.PermStatementEdit)).
+		Patch("/api/statement/{id}", handlers.UpdateStatement(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementStatus)).
+		Post("/api/statement/{id}/status", handlers.ChangeStatus(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementModerate)).
+		Post("/api/statement/{id}/suggestion/accept", handlers.AcceptSuggestion(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementModerate, usecase.PermStatementStatus)).
+		Get("/api/statement/{id}/transitions", handlers.GetStatusTransitions(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementModerate, usecase.PermStatementStatus)).
+		Get("/api/statement/{id}/history", handlers.GetStatementHistory(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementRead)).
+		Get("/api/statement/{id}", handlers.GetStatement(log, orderUseCase))
+	router.With(mwAuth.Require(usecase.PermStatementDelete)).
+		Delete("/api/statement/{id}", handlers.DeleteStatement(log, orderUseCase))
+
+	router.Group(func(r chi.Router) {
+		r.Use(mwAuth.Require(usecase.PermStatementRead))
+
+		r.Get("/api/analitic/categories", handlers.GetCategoriesAnalitic(log, orderUseCase))
+		r.Get("/api/analitic/categories/{district}", handlers.GetDistrictCategoriesAnalitic(log, orderUseCase))
+		r.Get("/api/analitic/period", handlers.GetPeriodAnalitic(log, orderUseCase))
+		r.Get("/api/analitic/district", handlers.GetDistrictAnalitic(log, orderUseCase))
+		r.Get("/api/analitic/crosstab", handlers.GetCrossTabAnalitic(log, orderUseCase))
+		r.Get("/api/analitic/resolution", handlers.GetResolutionAnalitic(log, orderUseCase, cfg.ResolutionSLA))
+		r.Get("/api/analitic/recs", handlers.GetRecomendations(log, orderUseCase, cfg.ResolutionSLA))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(mwAuth.Require(usecase.PermUserManage))
+
+		r.Get("/api/admin/users", handlers.ListUsers(log, authUseCase))
+		r.Post("/api/admin/users", handlers.CreateUser(log, authUseCase))
+		r.Put("/api/admin/users/{id}/role", handlers.SetUserRole(log, authUseCase))
+	})
+
+	return router
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"hack/internal/config"
+	"hack/internal/lib/health"
+	kafka "hack/internal/lib/kafka"
+	"hack/internal/lib/logger/sl"
+	"hack/internal/repository/cache"
+	"hack/internal/repository/memory"
+	"hack/internal/repository/postgres"
+	"hack/internal/repository/redis"
+	usecase "hack/internal/usecase"
+	"log/slog"
+	"os"
+)
+
+// memoryEventsCapacity is the number of statement events kept by the memory mode, nobody consumes them.
+const memoryEventsCapacity = 1000
+
+// repository is implemented by postgres.Storage and memory.Storage.
+type repository interface {
+	usecase.StatementRepository
+	usecase.UserRepository
+	usecase.OutboxRepository
+	usecase.IdempotencyKeyRepository
+}
+
+// dependencies are the storage, cache and brokers the use cases run on.
+type dependencies struct {
+	repo     repository
+	cache    usecase.CacheRepository
+	producer usecase.MessageBroker
+	events   usecase.MessageBroker
+	health   *health.Checker
+	// submissions is set in the memory mode: the API consumes its own submissions instead of cmd/worker.
+	submissions *kafka.MemoryBroker
+	closers     []closer
+}
+
+type closer struct {
+	name  string
+	close func() error
+}
+
+// mustLoadDependencies connects to PostgreSQL, Redis and Kafka, or creates their in-memory
+// replacements when cfg.Storage is config.StorageMemory.
+// If PostgreSQL is unavailable, os.Exit is executed. Redis and Kafka may be down: the health checker
+// keeps probing them, the cache is skipped and submissions go to the outbox until they are back.
+func mustLoadDependencies(log *slog.Logger, cfg *config.Config) dependencies {
+	if cfg.Storage == config.StorageMemory {
+		log.Warn("running with in-memory storage, data is lost on restart")
+		return newMemoryDependencies(log, cfg)
+	}
+
+	db, err := sql.Open("pgx", cfg.DSN())
+	if err != nil {
+		log.Error("failed to init storage", sl.Err(err))
+		os.Exit(1)
+	}
+	statementRepo := postgres.MustLoad(log, db, cfg.MigrationsPath)
+
+	redisConn := redis.New(cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.Password, cfg.DB)
+	kafkaProducer := kafka.NewProducer(cfg.Brokers, cfg.Topic)
+	eventsProducer := kafka.NewProducer(cfg.Brokers, cfg.EventsTopic)
+
+	checker := health.NewChecker(log, cfg.CheckInterval, cfg.CheckTimeout)
+	checker.Add("postgres", true, db.PingContext)
+	redisHealth := checker.Add("redis", false, redisConn.Ping)
+	kafkaHealth := checker.Add("kafka", false, kafkaProducer.Ping)
+
+	return dependencies{
+		repo:     statementRepo,
+		cache:    cache.NewLocal(cache.NewFallback(redisConn, redisHealth.Up), cfg.LocalSize, cfg.LocalTTL),
+		producer: kafkaProducer.Guard(kafkaHealth.Up),
+		events:   eventsProducer.Guard(kafkaHealth.Up),
+		health:   checker,
+		closers: []closer{
+			{"database", db.Close},
+			{"redis", redisConn.Close},
+			{"kafka producer", kafkaProducer.Close},
+			{"kafka events producer", eventsProducer.Close},
+		},
+	}
+}
+
+// newMemoryDependencies creates in-memory storage, cache and brokers. They are always available,
+// so the health checker has nothing to probe.
+func newMemoryDependencies(log *slog.Logger, cfg *config.Config) dependencies {
+	submissions := kafka.NewMemoryBroker(0)
+	events := kafka.NewMemoryBroker(memoryEventsCapacity)
+
+	return dependencies{
+		repo:        memory.New(),
+		cache:       memory.NewCache(),
+		producer:    submissions,
+		events:      events,
+		health:      health.NewChecker(log, cfg.CheckInterval, cfg.CheckTimeout),
+		submissions: submissions,
+		closers: []closer{
+			{"memory broker", submissions.Close},
+			{"memory events broker", events.Close},
+		},
+	}
+}
+
+// close releases the resources, logging failures.
+func (d dependencies) close(log *slog.Logger) {
+	for _, c := range d.closers {
+		if err := c.close(); err != nil {
+			log.Error("error closing "+c.name, sl.Err(err))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Command worker consumes statements published by the HTTP API to Kafka, suggests categories
+// for the ones going to moderation and stores them in PostgreSQL.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"hack/internal/app"
+	"hack/internal/config"
+	"hack/internal/lib/health"
+	kafka "hack/internal/lib/kafka"
+	"hack/internal/lib/logger/sl"
+	"hack/internal/lib/logger/slogpretty"
+	"hack/internal/repository/cache"
+	"hack/internal/repository/postgres"
+	"hack/internal/repository/redis"
+	usecase "hack/internal/usecase"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	cfg := config.MustLoad()
+
+	log := slogpretty.SetupLogger(cfg.Env)
+	log.Info("starting ingestion worker", slog.String("env", cfg.Env))
+
+	db, err := sql.Open("pgx", cfg.DSN())
+	if err != nil {
+		log.Error("failed to init storage", sl.Err(err))
+		os.Exit(1)
+	}
+
+	statementRepo := postgres.MustLoad(log, db, cfg.MigrationsPath)
+
+	// The worker only stores submissions, it needs the cache to drop lists and reports, but not the producer.
+	// Redis is optional like in the API.
+	redisConn := redis.New(cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.Password, cfg.DB)
+	checker := health.NewChecker(log, cfg.CheckInterval, cfg.CheckTimeout)
+	redisHealth := checker.Add("redis", false, redisConn.Ping)
+
+	// Statements going to moderation are classified here rather than in the HTTP request.
+	llmClient, err := app.NewLLMClient(cfg.LLM)
+	if err != nil {
+		log.Error("failed to init llm client", sl.Err(err))
+		os.Exit(1)
+	}
+	categoryClassifier := app.NewClassifier(log, cfg.Classifier, statementRepo, llmClient)
+	var classifier usecase.Classifier
+	if categoryClassifier != nil {
+		classifier = categoryClassifier
+	}
+
+	orderUseCase := usecase.NewStatementUseCase(statementRepo, cache.NewFallback(redisConn, redisHealth.Up), nil, nil, classifier)
+
+	consumer := kafka.NewConsumer(cfg.Brokers, cfg.ConsumerGroup, cfg.Topic, cfg.DLQTopic, kafka.RetryPolicy{
+		MaxAttempts:    cfg.Kafka.Retry.MaxAttempts,
+		InitialBackoff: cfg.Kafka.Retry.InitialBackoff,
+		MaxBackoff:     cfg.Kafka.Retry.MaxBackoff,
+		Multiplier:     cfg.Kafka.Retry.Multiplier,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go checker.Run(ctx)
+	if categoryClassifier != nil {
+		go categoryClassifier.Run(ctx)
+	}
+
+	log.Info("consuming statements",
+		slog.String("topic", cfg.Topic),
+		slog.String("consumer_group", cfg.ConsumerGroup),
+	)
+
+	handler := func(ctx context.Context, value []byte) error {
+		if err := orderUseCase.StoreSubmission(ctx, value); err != nil {
+			log.Error("failed to store submission", sl.Err(err))
+			if errors.Is(err, usecase.ErrInvalidSubmission) {
+				return kafka.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := consumer.Start(ctx, handler); err != nil {
+		log.Error("consumer stopped", sl.Err(err))
+	}
+
+	log.Info("closing resources...")
+	if err := consumer.Close(); err != nil {
+		log.Error("error closing kafka consumer", sl.Err(err))
+	}
+	if err := redisConn.Close(); err != nil {
+		log.Error("error closing redis", sl.Err(err))
+	}
+	if err := db.Close(); err != nil {
+		log.Error("error closing database", sl.Err(err))
+	}
+
+	log.Info("worker stopped gracefully")
+}
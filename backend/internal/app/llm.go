@@ -0,0 +1,45 @@
+// Package app builds the components configured the same way by the API and the ingestion worker.
+package app
+
+import (
+	"fmt"
+	"hack/internal/config"
+	"hack/internal/lib/llm"
+	usecase "hack/internal/usecase"
+	"log/slog"
+)
+
+// NewLLMClient creates the client of the configured LLM provider.
+func NewLLMClient(cfg config.LLM) (usecase.LLMClient, error) {
+	opts := llm.Options{
+		BaseURL:     cfg.BaseURL,
+		APIKey:      cfg.APIKey,
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		Timeout:     cfg.Timeout,
+	}
+
+	switch cfg.Provider {
+	case config.LLMMistral:
+		return llm.NewMistral(opts), nil
+	case config.LLMOpenAI:
+		return llm.NewOpenAI(opts), nil
+	case config.LLMStub:
+		return llm.NewStub(), nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q", cfg.Provider)
+	}
+}
+
+// NewClassifier creates the category classifier of the configured provider, nil if it is off.
+// The llm provider uses the recommendations' LLM client.
+func NewClassifier(log *slog.Logger, cfg config.Classifier, repo usecase.StatementRepository, llmClient usecase.LLMClient) *usecase.CategoryClassifier {
+	switch cfg.Provider {
+	case config.ClassifierOff:
+		return nil
+	case config.ClassifierLLM:
+		return usecase.NewCategoryClassifier(log, repo, llmClient, cfg.RetrainInterval, cfg.TrainingSize)
+	default:
+		return usecase.NewCategoryClassifier(log, repo, nil, cfg.RetrainInterval, cfg.TrainingSize)
+	}
+}
@@ -11,15 +11,53 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// Storage modes.
+const (
+	// StoragePostgres keeps data in PostgreSQL, caches in Redis and publishes to Kafka.
+	StoragePostgres = "postgres"
+	// StorageMemory keeps everything in process memory, no external services are needed.
+	// Submissions are stored by the API itself instead of cmd/worker.
+	StorageMemory = "memory"
+)
+
+// LLM providers.
+const (
+	// LLMMistral calls the Mistral API.
+	LLMMistral = "mistral"
+	// LLMOpenAI calls any OpenAI-compatible chat completions API at LLM.BaseURL.
+	LLMOpenAI = "openai"
+	// LLMStub answers locally with canned text, for tests and runs without network.
+	LLMStub = "stub"
+)
+
+// Classifier providers.
+const (
+	// ClassifierLocal suggests categories with the TF-IDF model trained on approved statements.
+	ClassifierLocal = "local"
+	// ClassifierLLM asks the LLM provider and falls back to the local model.
+	ClassifierLLM = "llm"
+	// ClassifierOff disables category suggestions.
+	ClassifierOff = "off"
+)
+
 // Config represents the root application configuration.
 type Config struct {
 	Env            string `yaml:"env" env-default:"local"`
+	Storage        string `yaml:"storage" env:"STORAGE" env-default:"postgres"`
 	StoragePath    string `yaml:"storage_path" env-required:"true"`
 	MigrationsPath string `yaml:"migrations_path"`
 	HTTPServer     `yaml:"http_server"`
 	Postgresql     `yaml:"postgresql"`
 	Redis          `yaml:"redis"`
 	Kafka          `yaml:"kafka"`
+	Auth           `yaml:"auth"`
+	Analitic       `yaml:"analitic"`
+	Outbox         `yaml:"outbox"`
+	Health         `yaml:"health"`
+	Cache          `yaml:"cache"`
+	LLM            LLM         `yaml:"llm"`
+	Classifier     Classifier  `yaml:"classifier"`
+	Idempotency    Idempotency `yaml:"idempotency"`
 }
 
 // HTTPServer holds HTTP server configuration.
@@ -53,12 +91,82 @@ type Kafka struct {
 	ConsumerGroup string   `yaml:"consumer_group"`
 	Topic         string   `yaml:"topic"`
 	DLQTopic      string   `yaml:"dlq_topic"`
+	EventsTopic   string   `yaml:"events_topic" env-default:"statement-events"`
+	Retry         Retry    `yaml:"retry"`
+}
+
+// Retry controls how the consumer retries a failed message before sending it to the DLQ.
+type Retry struct {
+	MaxAttempts    int           `yaml:"max_attempts" env-default:"5"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env-default:"500ms"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env-default:"30s"`
+	Multiplier     float64       `yaml:"multiplier" env-default:"2"`
+}
+
+// Auth contains session settings and the bootstrap moderator account.
+// The bootstrap account is created on startup when both username and password are set.
+type Auth struct {
+	SessionTTL    time.Duration `yaml:"session_ttl" env-default:"24h"`
+	CookieName    string        `yaml:"cookie_name" env-default:"session_id"`
+	CookieSecure  bool          `yaml:"cookie_secure" env:"AUTH_COOKIE_SECURE" env-default:"false"`
+	AdminUsername string        `yaml:"admin_username" env:"ADMIN_USERNAME"`
+	AdminPassword string        `yaml:"admin_password" env:"ADMIN_PASSWORD"`
+}
+
+// Analitic contains analytics settings.
+type Analitic struct {
+	// ResolutionSLA is the target time from creation to resolution of a statement.
+	ResolutionSLA time.Duration `yaml:"resolution_sla" env:"RESOLUTION_SLA" env-default:"72h"`
+}
+
+// Outbox contains settings of the relay publishing statement events to Kafka.
+type Outbox struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"100"`
+}
+
+// Health controls the background checks of PostgreSQL, Redis and Kafka.
+// Redis and Kafka are optional: while they are down the API runs degraded and keeps probing them.
+type Health struct {
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"5s"`
+	CheckTimeout  time.Duration `yaml:"check_timeout" env-default:"2s"`
+}
+
+// Cache controls the in-process LRU in front of Redis. Entries stay there for at most LocalTTL,
+// so other API instances see changes after LocalTTL at the latest.
+type Cache struct {
+	LocalSize int           `yaml:"local_size" env-default:"10000"`
+	LocalTTL  time.Duration `yaml:"local_ttl" env-default:"5s"`
+}
+
+// LLM selects the model generating recommendations.
+type LLM struct {
+	Provider string `yaml:"provider" env:"LLM_PROVIDER" env-default:"mistral"`
+	// BaseURL is the API root, required for the openai provider.
+	BaseURL     string        `yaml:"base_url" env:"LLM_BASE_URL"`
+	APIKey      string        `yaml:"api_key" env:"AI_API_KEY"`
+	Model       string        `yaml:"model" env:"LLM_MODEL" env-default:"devstral-latest"`
+	Temperature float64       `yaml:"temperature" env:"LLM_TEMPERATURE" env-default:"0.3"`
+	Timeout     time.Duration `yaml:"timeout" env:"LLM_TIMEOUT" env-default:"30s"`
+}
+
+// Classifier controls the category suggestions for statements awaiting moderation.
+// The local model is retrained on the latest TrainingSize approved statements every RetrainInterval.
+type Classifier struct {
+	Provider        string        `yaml:"provider" env:"CLASSIFIER_PROVIDER" env-default:"local"`
+	RetrainInterval time.Duration `yaml:"retrain_interval" env-default:"1h"`
+	TrainingSize    int           `yaml:"training_size" env-default:"5000"`
+}
+
+// Idempotency controls the removal of expired idempotency keys.
+type Idempotency struct {
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
 }
 
 // MustLoad loads configuration from YAML file and environment variables.
 // It panics if the config file is missing or cannot be read.
 func MustLoad() *Config {
-	configPath := "./configs/local.yaml" 
+	configPath := "./configs/local.yaml"
 	if configPath == "" {
 		log.Fatal("CONFIG_PATH is not set")
 	}
@@ -73,11 +181,31 @@ func MustLoad() *Config {
 		log.Fatalf("cannot read config: %s", err)
 	}
 
+	if cfg.Storage != StoragePostgres && cfg.Storage != StorageMemory {
+		log.Fatalf("unknown storage %q, want %q or %q", cfg.Storage, StoragePostgres, StorageMemory)
+	}
+
+	switch cfg.LLM.Provider {
+	case LLMMistral, LLMStub:
+	case LLMOpenAI:
+		if cfg.LLM.BaseURL == "" {
+			log.Fatalf("llm provider %q requires base_url", LLMOpenAI)
+		}
+	default:
+		log.Fatalf("unknown llm provider %q, want %q, %q or %q", cfg.LLM.Provider, LLMMistral, LLMOpenAI, LLMStub)
+	}
+
+	switch cfg.Classifier.Provider {
+	case ClassifierLocal, ClassifierLLM, ClassifierOff:
+	default:
+		log.Fatalf("unknown classifier provider %q, want %q, %q or %q", cfg.Classifier.Provider, ClassifierLocal, ClassifierLLM, ClassifierOff)
+	}
+
 	return &cfg
 }
 
 // DSN returns PostgreSQL connection string in the format required by pgx/driver.
 func (p Postgresql) DSN() string {
 	return fmt.Sprintf("user=%s password=%s dbname=%s sslmode=%s host=%s port=%d",
-        p.User, p.Password, p.DBname, p.SSLmode, p.Host, p.Port)
-}
\ No newline at end of file
+		p.User, p.Password, p.DBname, p.SSLmode, p.Host, p.Port)
+}
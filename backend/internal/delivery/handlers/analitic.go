@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	resp "hack/internal/lib/api/response"
+	"hack/internal/models"
+	usecase "hack/internal/usecase"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// parseAnaliticFilter reads the statement filter for analytics endpoints.
+// Without admin_status only approved statements are counted.
+func parseAnaliticFilter(q url.Values) (models.StatementFilter, error) {
+	filter, err := parseStatementFilter(q)
+	if err != nil {
+		return filter, err
+	}
+	if !q.Has("admin_status") {
+		approved := false
+		filter.AdminStatus = &approved
+	}
+	return filter, nil
+}
+
+// analiticError writes the error response of an analytics endpoint.
+func analiticError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, usecase.ErrInvalidQuery) {
+		render.Status(r, http.StatusBadRequest)
+	} else {
+		render.Status(r, http.StatusInternalServerError)
+	}
+	render.JSON(w, r, resp.Error(err.Error()))
+}
+
+// GetPeriodAnalitic returns HTTP handler for GET /api/analitic/period?from=&to=&bucket=day|week|month.
+// The response is an ordered series with zero-filled gaps.
+func GetPeriodAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.analitic.GetPeriodAnalitic"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q := r.URL.Query()
+
+		filter, err := parseAnaliticFilter(q)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		series, err := statementUseCase.GetPeriodAnalitic(r.Context(), filter, models.Bucket(q.Get("bucket")))
+		if err != nil {
+			log.Error("failed to get period analitic", "op", op, "error", err)
+			analiticError(w, r, err)
+			return
+		}
+
+		log.Info("period analitic getting success")
+		render.JSON(w, r, series)
+	}
+}
+
+// allDistricts is the legacy value of {district} in /api/analitic/categories/{district}
+// which means "all districts".
+const allDistricts = "1"
+
+// GetCategoriesAnalitic returns HTTP handler for
+// GET /api/analitic/categories?district=&status=&source=&from=&to=.
+// Every filter accepts several values, without a filter all approved statements are counted.
+func GetCategoriesAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.analitic.GetCategoriesAnalitic"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, err := parseAnaliticFilter(r.URL.Query())
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		getCategoriesAnalitic(w, r, log, statementUseCase, filter)
+	}
+}
+
+// GetDistrictCategoriesAnalitic returns HTTP handler for the legacy
+// GET /api/analitic/categories/{district}. It is an alias of GetCategoriesAnalitic
+// with a single district, district "1" stands for all districts.
+func GetDistrictCategoriesAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.analitic.GetDistrictCategoriesAnalitic"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, err := parseAnaliticFilter(r.URL.Query())
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		if district := chi.URLParam(r, "district"); district != allDistricts {
+			filter.Districts = []string{district}
+		}
+
+		getCategoriesAnalitic(w, r, log, statementUseCase, filter)
+	}
+}
+
+func getCategoriesAnalitic(w http.ResponseWriter, r *http.Request, log *slog.Logger, statementUseCase *usecase.StatementUseCase, filter models.StatementFilter) {
+	analitic, err := statementUseCase.GetCategoriesAnalitic(r.Context(), filter)
+	if err != nil {
+		log.Error("failed to get categories analitic", "error", err)
+		analiticError(w, r, err)
+		return
+	}
+
+	log.Info("categories analitic getting success")
+	render.JSON(w, r, analitic)
+}
+
+// GetCrossTabAnalitic returns HTTP handler for GET /api/analitic/crosstab.
+// It accepts the same filters as the other analytics endpoints.
+func GetCrossTabAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.analitic.GetCrossTabAnalitic"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, err := parseAnaliticFilter(r.URL.Query())
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		tab, err := statementUseCase.GetCrossTabAnalitic(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to get cross tab analitic", "op", op, "error", err)
+			analiticError(w, r, err)
+			return
+		}
+
+		log.Info("cross tab analitic getting success")
+		render.JSON(w, r, tab)
+	}
+}
+
+// GetResolutionAnalitic returns HTTP handler for GET /api/analitic/resolution?group_by=&sla=.
+// Without sla the configured defaultSLA is used.
+func GetResolutionAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase, defaultSLA time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.analitic.GetResolutionAnalitic"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q := r.URL.Query()
+
+		filter, err := parseAnaliticFilter(q)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		query := models.ResolutionQuery{
+			Filter:  filter,
+			GroupBy: models.ResolutionGroup(q.Get("group_by")),
+			SLA:     defaultSLA,
+		}
+		if v := q.Get("sla"); v != "" {
+			if query.SLA, err = time.ParseDuration(v); err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("invalid sla parameter"))
+				return
+			}
+		}
+
+		report, err := statementUseCase.GetResolutionAnalitic(r.Context(), query)
+		if err != nil {
+			log.Error("failed to get resolution analitic", "op", op, "error", err)
+			analiticError(w, r, err)
+			return
+		}
+
+		log.Info("resolution analitic getting success")
+		render.JSON(w, r, report)
+	}
+}
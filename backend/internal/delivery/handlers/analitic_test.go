@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+func Test_parseAnaliticFilter(t *testing.T) {
+	approved := false
+	pending := true
+
+	tests := []struct {
+		name    string
+		query   string
+		want    models.StatementFilter
+		wantErr bool
+	}{
+		{
+			name:  "no filter counts approved statements",
+			query: "",
+			want:  models.StatementFilter{AdminStatus: &approved},
+		},
+		{
+			name:  "repeated and comma-separated values",
+			query: "district=Невский&district=Центральный,%20Адмиралтейский&status=Новое,Решено&source=portal",
+			want: models.StatementFilter{
+				Districts:   []string{"Невский", "Центральный", "Адмиралтейский"},
+				Statuses:    []models.Status{models.StatusNew, models.StatusResolved},
+				Sources:     []string{"portal"},
+				AdminStatus: &approved,
+			},
+		},
+		{
+			name:  "date range includes the whole last day",
+			query: "from=2023-12-01&to=2023-12-31",
+			want: models.StatementFilter{
+				From:        time.Date(2023, 12, 1, 0, 0, 0, 0, models.CityLocation),
+				To:          time.Date(2024, 1, 1, 0, 0, 0, 0, models.CityLocation),
+				AdminStatus: &approved,
+			},
+		},
+		{
+			name:  "pending statements",
+			query: "admin_status=true",
+			want:  models.StatementFilter{AdminStatus: &pending},
+		},
+		{
+			name:  "all statements",
+			query: "admin_status=all",
+			want:  models.StatementFilter{},
+		},
+		{name: "bad date", query: "from=01.12.2023", wantErr: true},
+		{name: "bad admin_status", query: "admin_status=maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := parseAnaliticFilter(q)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseAnaliticFilter() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAnaliticFilter() error = %v", err)
+			}
+			if !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) {
+				t.Errorf("range = %v - %v, want %v - %v", got.From, got.To, tt.want.From, tt.want.To)
+			}
+			got.From, got.To = tt.want.From, tt.want.To
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAnaliticFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
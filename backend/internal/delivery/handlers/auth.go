@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	mwAuth "hack/internal/delivery/middleware/auth"
+	resp "hack/internal/lib/api/response"
+	"hack/internal/models"
+	usecase "hack/internal/usecase"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+// SessionCookie describes the cookie that carries the session token.
+type SessionCookie struct {
+	Name   string
+	Secure bool
+}
+
+func (c SessionCookie) new(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     c.Name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   c.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Login returns HTTP handler that checks username and password and sets the session cookie.
+func Login(log *slog.Logger, authUseCase *usecase.AuthUseCase, cookie SessionCookie) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.Login"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var credentials models.Credentials
+		if err := render.DecodeJSON(r.Body, &credentials); err != nil {
+			log.Error("failed to unmarshal credentials", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		token, user, err := authUseCase.Login(r.Context(), credentials)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidCredentials) {
+				log.Info("invalid credentials", slog.String("username", credentials.Username))
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, resp.Error(usecase.ErrInvalidCredentials.Error()))
+				return
+			}
+			log.Error("failed to login", "op", op, "error", err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		http.SetCookie(w, cookie.new(token, time.Now().Add(authUseCase.SessionTTL())))
+
+		log.Info("login success", slog.Int64("user_id", user.ID))
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// Logout returns HTTP handler that closes the current session and clears the cookie.
+func Logout(log *slog.Logger, authUseCase *usecase.AuthUseCase, cookie SessionCookie) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.Logout"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if c, err := r.Cookie(cookie.Name); err == nil {
+			if err := authUseCase.Logout(r.Context(), c.Value); err != nil {
+				log.Error("failed to logout", "op", op, "error", err)
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("internal error"))
+				return
+			}
+		}
+
+		expired := cookie.new("", time.Unix(0, 0))
+		expired.MaxAge = -1
+		http.SetCookie(w, expired)
+
+		log.Info("logout success")
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// Me returns HTTP handler that responds with the logged in user.
+func Me(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auth.Me"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		user, ok := mwAuth.UserFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("authentication required"))
+			return
+		}
+
+		log.Info("user getting success")
+		render.JSON(w, r, user)
+	}
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import "net/http"
+
+// errorMessage returns the error text sent to the client with the status.
+// Internal errors are only logged: their wrapped causes would expose queries and drivers.
+func errorMessage(status int, err error) string {
+	if status == http.StatusInternalServerError {
+		return "internal error"
+	}
+	return err.Error()
+}
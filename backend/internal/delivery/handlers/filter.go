@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"hack/internal/models"
+	usecase "hack/internal/usecase"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryValues returns all values of a repeated or comma-separated query parameter.
+func queryValues(q url.Values, key string) []string {
+	var values []string
+	for _, raw := range q[key] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// parseStatementFilter reads the filter shared by the list and analytics endpoints:
+// district, category, subcategory, status, source, admin_status, from and to.
+// A plain date in "to" includes the whole day.
+func parseStatementFilter(q url.Values) (models.StatementFilter, error) {
+	filter := models.StatementFilter{
+		Districts:     queryValues(q, "district"),
+		Categories:    queryValues(q, "category"),
+		Subcategories: queryValues(q, "subcategory"),
+		Sources:       queryValues(q, "source"),
+	}
+
+	for _, status := range queryValues(q, "status") {
+		filter.Statuses = append(filter.Statuses, models.Status(status))
+	}
+
+	if v := q.Get("admin_status"); v != "" && v != "all" {
+		adminStatus, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("admin_status: expected true, false or all")
+		}
+		filter.AdminStatus = &adminStatus
+	}
+
+	var err error
+	if filter.From, err = models.ParseTime(q.Get("from")); err != nil {
+		return filter, fmt.Errorf("from: expected RFC 3339 or YYYY-MM-DD")
+	}
+	if filter.To, err = models.ParseTime(q.Get("to")); err != nil {
+		return filter, fmt.Errorf("to: expected RFC 3339 or YYYY-MM-DD")
+	}
+	if _, dateErr := time.Parse(models.DateLayout, q.Get("to")); dateErr == nil {
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+// parseStatementQuery reads the filter plus sort ("created_at", "-updated_at", "id"), limit and cursor.
+func parseStatementQuery(q url.Values) (models.StatementQuery, error) {
+	filter, err := parseStatementFilter(q)
+	if err != nil {
+		return models.StatementQuery{}, err
+	}
+
+	query := models.StatementQuery{Filter: filter}
+
+	if sort := q.Get("sort"); sort != "" {
+		query.Desc = strings.HasPrefix(sort, "-")
+		query.SortBy = models.SortField(strings.TrimPrefix(sort, "-"))
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil {
+			return models.StatementQuery{}, fmt.Errorf("limit: expected a number")
+		}
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := usecase.DecodeCursor(v)
+		if err != nil {
+			return models.StatementQuery{}, fmt.Errorf("cursor: %w", err)
+		}
+		query.After = &cursor
+	}
+
+	return query, nil
+}
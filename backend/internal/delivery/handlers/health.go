@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"hack/internal/lib/health"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// Live returns HTTP handler for the liveness probe: the process is running.
+func Live() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, health.Report{Status: health.StatusOK, Components: []health.ComponentReport{}})
+	}
+}
+
+// Ready returns HTTP handler for the readiness probe: the state of every dependency.
+// A degraded service is still ready and gets 200, 503 is returned only while a critical dependency is down.
+func Ready(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Report()
+		if report.Status == health.StatusDown {
+			render.Status(r, http.StatusServiceUnavailable)
+		}
+		render.JSON(w, r, report)
+	}
+}
@@ -4,20 +4,34 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	mwAuth "hack/internal/delivery/middleware/auth"
 	resp "hack/internal/lib/api/response"
 	"hack/internal/models"
+	"hack/internal/repository"
 	usecase "hack/internal/usecase"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
-// NewOrder returns HTTP handler for creating a new order.
-// It decodes JSON request body, validates it via use case and returns appropriate response.
+// acceptedResponse is the body of 202 Accepted for statements queued for the ingestion worker.
+type acceptedResponse struct {
+	resp.Response
+	TrackingID string `json:"tracking_id"`
+}
+
+// NewStatement returns HTTP handler for creating statements.
+// It decodes JSON request body, validates it via use case and responds with 202 Accepted and a tracking ID,
+// the statements are stored asynchronously by the ingestion worker.
+// A retry with the same Idempotency-Key header gets the original tracking ID and an Idempotent-Replayed header;
+// the key sent with other statements is rejected with 422 Unprocessable Entity.
+// Guests cannot use the header, it is ignored for them.
 func NewStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.statement.NewStatement"
@@ -33,18 +47,39 @@ func NewStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCase)
 
 		if err := render.DecodeJSON(r.Body, &statements); err != nil {
 			log.Error("failed to unmarshal statement", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		if err := statementUseCase.CreateStatement(ctx, statements); err != nil {
+		actor, _ := mwAuth.UserFromContext(ctx)
+
+		trackingID, replayed, err := statementUseCase.CreateStatement(ctx, actor, r.Header.Get("Idempotency-Key"), statements)
+		if err != nil {
 			log.Error("failed create statement", "op", op, "error", err)
+			render.Status(r, createErrorStatus(err))
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		log.Info("statement creating success")
-		render.JSON(w, r, resp.OK())
+		log.Info("statement accepted", slog.String("tracking_id", trackingID), slog.Bool("replayed", replayed))
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, acceptedResponse{Response: resp.OK(), TrackingID: trackingID})
+	}
+}
+
+// createErrorStatus maps CreateStatement errors to HTTP status codes.
+func createErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrInvalidStatement), errors.Is(err, usecase.ErrInvalidIdempotencyKey):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
 	}
 }
 
@@ -100,8 +135,11 @@ func UpdateStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCas
 			return
 		}
 
-		if err := statementUseCase.UpdateStatement(ctx, statements); err != nil {
+		actor, _ := mwAuth.UserFromContext(ctx)
+
+		if err := statementUseCase.UpdateStatement(ctx, actor, statements); err != nil {
 			log.Error("failed create statement", "op", op, "error", err)
+			render.Status(r, statusErrorStatus(err))
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
@@ -111,6 +149,8 @@ func UpdateStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCas
 	}
 }
 
+// DeleteStatement returns HTTP handler that deletes the statement from the URL.
+// It responds with 403 Forbidden without the delete permission and 404 Not Found for an unknown statement.
 func DeleteStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.statement.DeleteStatement"
@@ -120,18 +160,20 @@ func DeleteStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCas
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		//error track
-		statementUID := chi.URLParam(r, "id")
-		if statementUID == "" {
-			http.Error(w, "id parameter missing", http.StatusBadRequest)
+		key, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id parameter"))
 			return
 		}
-		key, _ := strconv.Atoi(statementUID)
 
-		err := statementUseCase.DeleteStatement(context.Background(), key)
-		if err != nil {
+		actor, _ := mwAuth.UserFromContext(r.Context())
+
+		if err := statementUseCase.DeleteStatement(r.Context(), actor, key); err != nil {
 			log.Error("failed to delete statement", "op", op, "error", err)
-			render.JSON(w, r, resp.Error(err.Error()))
+			status := deleteErrorStatus(err)
+			render.Status(r, status)
+			render.JSON(w, r, resp.Error(errorMessage(status, err)))
 			return
 		}
 
@@ -140,53 +182,107 @@ func DeleteStatement(log *slog.Logger, statementUseCase *usecase.StatementUseCas
 	}
 }
 
-func GetAllNewStatements(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.statement.GetAllStatements"
+// deleteErrorStatus maps DeleteStatement errors to HTTP status codes.
+func deleteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
 
-		var statements []models.Statement
+// ListStatements returns HTTP handler for the paginated statement list.
+// Without admin_status only statements waiting for moderation are returned.
+func ListStatements(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.statement.ListStatements"
 
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		statements, err := statementUseCase.GetAllNewStatements(context.Background())
+		query, err := parseStatementQuery(r.URL.Query())
 		if err != nil {
-			log.Error("failed to unmarshal statement", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
+		if !r.URL.Query().Has("admin_status") {
+			pending := true
+			query.Filter.AdminStatus = &pending
+		}
 
-		log.Info("statement getting success")
-		render.JSON(w, r, statements)
+		page, err := statementUseCase.ListStatements(r.Context(), query)
+		if err != nil {
+			log.Error("failed to list statements", "op", op, "error", err)
+			if errors.Is(err, usecase.ErrInvalidQuery) {
+				render.Status(r, http.StatusBadRequest)
+			} else {
+				render.Status(r, http.StatusInternalServerError)
+			}
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		log.Info("statements getting success", slog.Int("count", len(page.Items)))
+		render.JSON(w, r, page)
 	}
 }
 
-func GetCategoriesAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+// SearchStatements returns HTTP handler for GET /api/statement/search?q=.
+// It accepts the same filters as ListStatements plus limit and offset.
+func SearchStatements(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.analitic.GetCategoriesAnalitic"
+		const op = "handlers.statement.SearchStatements"
 
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		district := chi.URLParam(r, "district")
-		if district == "" {
-			http.Error(w, "id parameter missing", http.StatusBadRequest)
+		q := r.URL.Query()
+
+		filter, err := parseStatementFilter(q)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		analitic, err := statementUseCase.GetCategoriesAnalitic(context.Background(), district)
+		query := models.SearchQuery{Text: q.Get("q"), Filter: filter}
+		if v := q.Get("limit"); v != "" {
+			if query.Limit, err = strconv.Atoi(v); err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("limit: expected a number"))
+				return
+			}
+		}
+		if v := q.Get("offset"); v != "" {
+			if query.Offset, err = strconv.Atoi(v); err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("offset: expected a number"))
+				return
+			}
+		}
+
+		result, err := statementUseCase.SearchStatements(r.Context(), query)
 		if err != nil {
-			log.Error("failed to unmarshal statement", "op", op, "error", err)
+			log.Error("failed to search statements", "op", op, "error", err)
+			if errors.Is(err, usecase.ErrInvalidQuery) {
+				render.Status(r, http.StatusBadRequest)
+			} else {
+				render.Status(r, http.StatusInternalServerError)
+			}
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		log.Info("statement getting success")
-		render.JSON(w, r, analitic)
+		log.Info("statements search success", slog.Int("total", result.Total))
+		render.JSON(w, r, result)
 	}
 }
 
@@ -211,54 +307,251 @@ func GetDistrictAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUs
 	}
 }
 
-func GetPeriodAnalitic(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+// GetRecomendations returns HTTP handler for GET /api/analitic/recs?c=&district=&category=.
+// It responds with c recommendations grounded in the approved statements matching the analytics filter,
+// 502 Bad Gateway when the model is unreachable or fails to produce valid ones.
+func GetRecomendations(log *slog.Logger, statementUseCase *usecase.StatementUseCase, sla time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.analitic.GetPeriodAnalitic"
+		const op = "handlers.analitic.GetRecomendations"
 
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		analitic, err := statementUseCase.GetPeriodAnalitic(context.Background())
+		q := r.URL.Query()
+		count, err := strconv.Atoi(q.Get("c"))
+
 		if err != nil {
-			log.Error("failed to unmarshal statement", "op", op, "error", err)
+			log.Error("failed convert count query param", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		log.Info("statement getting success")
-		render.JSON(w, r, analitic)
+		filter, err := parseAnaliticFilter(q)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		query := models.RecommendationQuery{Filter: filter, Count: count, SLA: sla}
+		recomendations, err := statementUseCase.GetRecomendations(r.Context(), query)
+		if err != nil {
+			log.Error("failed to get recomendations", "op", op, "error", err)
+			status := recomendationsErrorStatus(err)
+			render.Status(r, status)
+			render.JSON(w, r, resp.Error(errorMessage(status, err)))
+			return
+		}
+
+		log.Info("recomendations getting success")
+
+		render.JSON(w, r, recomendations)
+	}
+}
+
+// recomendationsErrorStatus maps recommendation errors to HTTP status codes.
+func recomendationsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrLLMNotConfigured):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, usecase.ErrLLMUnavailable), errors.Is(err, usecase.ErrInvalidRecommendations):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
 	}
 }
 
-func GetRecomendations(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+// statusErrorStatus maps status workflow errors to HTTP status codes.
+func statusErrorStatus(err error) int {
+	var transitionErr *usecase.TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrInvalidStatement), errors.Is(err, usecase.ErrStatusChangeNotAllowed):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ChangeStatus returns HTTP handler that moves a statement to a new status.
+// Illegal transitions are rejected with 422 Unprocessable Entity.
+func ChangeStatus(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.analitic.GetRecomendations"
+		const op = "handlers.statement.ChangeStatus"
+
+		ctx := r.Context()
 
 		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		query := r.URL.Query().Get("c")
-		count, err := strconv.Atoi(query)
+		key, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id parameter"))
+			return
+		}
+
+		var change models.StatusChange
+		if err := render.DecodeJSON(r.Body, &change); err != nil {
+			log.Error("failed to unmarshal status change", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
 
+		actor, _ := mwAuth.UserFromContext(ctx)
+
+		transition, err := statementUseCase.ChangeStatus(ctx, actor, key, change)
 		if err != nil {
-			log.Error("failed convert count query param", "op", op, "error", err)
+			log.Error("failed to change status", "op", op, "error", err)
+			render.Status(r, statusErrorStatus(err))
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		recomendations, err := statementUseCase.GetRecomendations(context.Background(), count)
+		log.Info("status changing success",
+			slog.Int("statement_id", key),
+			slog.String("from", string(transition.From)),
+			slog.String("to", string(transition.To)),
+		)
+		render.JSON(w, r, transition)
+	}
+}
+
+// suggestionErrorStatus maps errors of accepting a category suggestion to HTTP status codes.
+func suggestionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrNoSuggestion):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// AcceptSuggestion returns HTTP handler that moves a statement to the category suggested by the classifier.
+// It responds with the updated statement, 409 Conflict if there is no suggestion.
+func AcceptSuggestion(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.statement.AcceptSuggestion"
+
+		ctx := r.Context()
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		key, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id parameter"))
+			return
+		}
+
+		actor, _ := mwAuth.UserFromContext(ctx)
+
+		statement, err := statementUseCase.AcceptSuggestion(ctx, actor, key)
 		if err != nil {
-			log.Error("failed to get recomendations", "op", op, "error", err)
+			log.Error("failed to accept suggestion", "op", op, "error", err)
+			render.Status(r, suggestionErrorStatus(err))
 			render.JSON(w, r, resp.Error(err.Error()))
 			return
 		}
 
-		log.Info("recomendations getting success")
+		log.Info("suggestion accepting success",
+			slog.Int("statement_id", key),
+			slog.String("category", statement.Category),
+		)
+		render.JSON(w, r, statement)
+	}
+}
 
-		render.JSON(w, r, recomendations)
+// GetStatusTransitions returns HTTP handler that lists status changes of a statement.
+// It responds with 404 Not Found for an unknown statement.
+func GetStatusTransitions(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.statement.GetStatusTransitions"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		key, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id parameter"))
+			return
+		}
+
+		transitions, err := statementUseCase.GetStatusTransitions(r.Context(), key)
+		if err != nil {
+			log.Error("failed to get transitions", "op", op, "error", err)
+			status := statusErrorStatus(err)
+			render.Status(r, status)
+			render.JSON(w, r, resp.Error(errorMessage(status, err)))
+			return
+		}
+
+		log.Info("transitions getting success")
+		render.JSON(w, r, transitions)
+	}
+}
+
+// historyErrorStatus maps errors of reading the audit trail to HTTP status codes.
+func historyErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// GetStatementHistory returns HTTP handler that lists the audit trail of a statement.
+// It responds with 404 Not Found for an unknown statement.
+func GetStatementHistory(log *slog.Logger, statementUseCase *usecase.StatementUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.statement.GetStatementHistory"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		key, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id parameter"))
+			return
+		}
+
+		events, err := statementUseCase.GetStatementHistory(r.Context(), key)
+		if err != nil {
+			log.Error("failed to get history", "op", op, "error", err)
+			status := historyErrorStatus(err)
+			render.Status(r, status)
+			render.JSON(w, r, resp.Error(errorMessage(status, err)))
+			return
+		}
+
+		log.Info("history getting success")
+		render.JSON(w, r, events)
 	}
 }
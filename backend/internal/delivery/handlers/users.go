@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	mwAuth "hack/internal/delivery/middleware/auth"
+	resp "hack/internal/lib/api/response"
+	"hack/internal/models"
+	"hack/internal/repository"
+	usecase "hack/internal/usecase"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// roleRequest is the body of PUT /api/admin/users/{id}/role.
+type roleRequest struct {
+	Role models.Role `json:"role"`
+}
+
+// userErrorStatus maps admin API errors to HTTP status codes.
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidUser):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListUsers returns HTTP handler that lists all accounts.
+func ListUsers(log *slog.Logger, authUseCase *usecase.AuthUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.users.ListUsers"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		users, err := authUseCase.ListUsers(r.Context())
+		if err != nil {
+			log.Error("failed to list users", "op", op, "error", err)
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		log.Info("users getting success")
+		render.JSON(w, r, users)
+	}
+}
+
+// CreateUser returns HTTP handler that creates an account with the given role.
+func CreateUser(log *slog.Logger, authUseCase *usecase.AuthUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.users.CreateUser"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var newUser models.NewUser
+		if err := render.DecodeJSON(r.Body, &newUser); err != nil {
+			log.Error("failed to unmarshal user", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		user, err := authUseCase.Register(r.Context(), newUser)
+		if err != nil {
+			log.Error("failed to create user", "op", op, "error", err)
+			status := userErrorStatus(err)
+			render.Status(r, status)
+			render.JSON(w, r, resp.Error(errorMessage(status, err)))
+			return
+		}
+
+		log.Info("user creating success", slog.Int64("user_id", user.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, user)
+	}
+}
+
+// SetUserRole returns HTTP handler that assigns a role to the user from the URL.
+func SetUserRole(log *slog.Logger, authUseCase *usecase.AuthUseCase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.users.SetUserRole"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid id parameter"))
+			return
+		}
+
+		var req roleRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to unmarshal role", "op", op, "error", err)
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		actor, _ := mwAuth.UserFromContext(r.Context())
+
+		if err := authUseCase.SetUserRole(r.Context(), actor, userID, req.Role); err != nil {
+			log.Error("failed to set user role", "op", op, "error", err)
+			status := userErrorStatus(err)
+			render.Status(r, status)
+			render.JSON(w, r, resp.Error(errorMessage(status, err)))
+			return
+		}
+
+		log.Info("user role updating success", slog.Int64("user_id", userID), slog.String("role", string(req.Role)))
+		render.JSON(w, r, resp.OK())
+	}
+}
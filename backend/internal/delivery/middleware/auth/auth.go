@@ -0,0 +1,116 @@
+// Package auth provides chi middlewares that resolve the session cookie
+// into a user and guard routes which require a logged in user.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	resp "hack/internal/lib/api/response"
+	"hack/internal/lib/logger/sl"
+	"hack/internal/models"
+	usecase "hack/internal/usecase"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+// Authenticator resolves a raw session token into its owner.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (models.User, error)
+}
+
+type ctxKey struct{}
+
+// UserFromContext returns the user stored in the context by New.
+func UserFromContext(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(ctxKey{}).(models.User)
+	return user, ok
+}
+
+// WithUser returns a copy of ctx carrying the user.
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, ctxKey{}, user)
+}
+
+// New reads the session cookie and, when it is valid, puts the user into the request context.
+// Anonymous requests pass through untouched; use RequireUser to reject them.
+func New(log *slog.Logger, authenticator Authenticator, cookieName string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/auth"),
+		)
+
+		log.Info("auth middleware enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(cookieName)
+			if err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := authenticator.Authenticate(r.Context(), cookie.Value)
+			if err != nil {
+				if !errors.Is(err, usecase.ErrUnauthorized) {
+					log.Error("failed to authenticate session",
+						sl.Err(err),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+					)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireUser responds with 401 Unauthorized when no user is logged in.
+func RequireUser(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := UserFromContext(r.Context()); !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error("authentication required"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// Require enforces the permission matrix: the caller's role must be granted at least one of perms.
+// Anonymous callers are checked as guests and get 401 Unauthorized when guests lack the permission,
+// logged in users without the permission get 403 Forbidden.
+func Require(perms ...usecase.Permission) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+
+			for _, perm := range perms {
+				if usecase.Can(user.Role, perm) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if !ok {
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, resp.Error("authentication required"))
+				return
+			}
+
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Error("permission denied"))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
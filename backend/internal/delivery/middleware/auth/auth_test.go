@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hack/internal/models"
+	usecase "hack/internal/usecase"
+)
+
+// tokenAuthenticator knows one session token per user.
+type tokenAuthenticator map[string]models.User
+
+func (a tokenAuthenticator) Authenticate(_ context.Context, token string) (models.User, error) {
+	user, ok := a[token]
+	if !ok {
+		return models.User{}, usecase.ErrUnauthorized
+	}
+	return user, nil
+}
+
+// serve runs the request with the session cookie, if token is not empty, through New and guard.
+func serve(guard func(http.Handler) http.Handler, token string) *httptest.ResponseRecorder {
+	authenticator := tokenAuthenticator{
+		"citizen-token":   {ID: 1, Username: "citizen", Role: models.RoleCitizen},
+		"moderator-token": {ID: 2, Username: "moderator", Role: models.RoleModerator},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: token})
+	}
+	rec := httptest.NewRecorder()
+	New(log, authenticator, "session_id")(guard(ok)).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequireUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "anonymous", want: http.StatusUnauthorized},
+		{name: "unknown session", token: "expired-token", want: http.StatusUnauthorized},
+		{name: "logged in", token: "citizen-token", want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(RequireUser, tt.token).Code; got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name  string
+		perms []usecase.Permission
+		token string
+		want  int
+	}{
+		{name: "guest allowed", perms: []usecase.Permission{usecase.PermStatementRead}, want: http.StatusNoContent},
+		{name: "guest denied", perms: []usecase.Permission{usecase.PermStatementModerate}, want: http.StatusUnauthorized},
+		{name: "unknown session is a guest", perms: []usecase.Permission{usecase.PermStatementModerate}, token: "expired-token", want: http.StatusUnauthorized},
+		{name: "role denied", perms: []usecase.Permission{usecase.PermStatementModerate}, token: "citizen-token", want: http.StatusForbidden},
+		{name: "role allowed", perms: []usecase.Permission{usecase.PermStatementModerate}, token: "moderator-token", want: http.StatusNoContent},
+		{name: "any of perms", perms: []usecase.Permission{usecase.PermUserManage, usecase.PermStatementModerate}, token: "moderator-token", want: http.StatusNoContent},
+		{name: "admin only", perms: []usecase.Permission{usecase.PermUserManage}, token: "moderator-token", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serve(Require(tt.perms...), tt.token).Code; got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
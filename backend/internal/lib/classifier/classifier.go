@@ -0,0 +1,174 @@
+// Package classifier is a small text classifier: TF-IDF vectors of word stems and the nearest
+// label centroid by cosine similarity. It is trained in memory in milliseconds on a few thousand
+// texts, which is enough to suggest a category of a short complaint.
+package classifier
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Example is a text with its label.
+type Example struct {
+	Text  string
+	Label string
+}
+
+// Prediction is the most likely label of a text. Confidence is the share of the label
+// in the similarities to all labels, from 0 to 1.
+type Prediction struct {
+	Label      string
+	Confidence float64
+}
+
+// vector is a sparse L2-normalized term vector.
+type vector map[string]float64
+
+// Model is a trained classifier. It is immutable and safe for concurrent use.
+type Model struct {
+	idf       map[string]float64
+	labels    []string
+	centroids map[string]vector
+}
+
+// Train builds a model from the examples. Examples without terms are skipped.
+func Train(examples []Example) *Model {
+	m := &Model{idf: make(map[string]float64), centroids: make(map[string]vector)}
+
+	docs := make([]map[string]int, 0, len(examples))
+	labels := make([]string, 0, len(examples))
+	df := make(map[string]int)
+	for _, ex := range examples {
+		counts := termCounts(ex.Text)
+		if len(counts) == 0 || ex.Label == "" {
+			continue
+		}
+		for term := range counts {
+			df[term]++
+		}
+		docs = append(docs, counts)
+		labels = append(labels, ex.Label)
+	}
+
+	// Smoothed IDF: terms of every document still weigh 1, unseen terms are ignored at prediction.
+	n := float64(len(docs))
+	for term, count := range df {
+		m.idf[term] = math.Log((1+n)/(1+float64(count))) + 1
+	}
+
+	sums := make(map[string]vector)
+	for i, counts := range docs {
+		sum, ok := sums[labels[i]]
+		if !ok {
+			sum = make(vector)
+			sums[labels[i]] = sum
+			m.labels = append(m.labels, labels[i])
+		}
+		for term, w := range m.vectorize(counts) {
+			sum[term] += w
+		}
+	}
+	for label, sum := range sums {
+		m.centroids[label] = normalize(sum)
+	}
+	slices.Sort(m.labels)
+
+	return m
+}
+
+// Labels returns the labels the model knows, sorted.
+func (m *Model) Labels() []string {
+	if m == nil {
+		return nil
+	}
+	return slices.Clone(m.labels)
+}
+
+// Predict returns the label whose centroid is the closest to the text.
+// It reports false when the text shares no terms with the training set.
+func (m *Model) Predict(text string) (Prediction, bool) {
+	if m == nil || len(m.labels) == 0 {
+		return Prediction{}, false
+	}
+	doc := m.vectorize(termCounts(text))
+	if len(doc) == 0 {
+		return Prediction{}, false
+	}
+
+	var best Prediction
+	var total float64
+	for _, label := range m.labels {
+		sim := dot(doc, m.centroids[label])
+		total += sim
+		if sim > best.Confidence {
+			best = Prediction{Label: label, Confidence: sim}
+		}
+	}
+	if best.Label == "" {
+		return Prediction{}, false
+	}
+	best.Confidence /= total
+
+	return best, true
+}
+
+// vectorize weighs term counts by TF-IDF, dropping terms unknown to the model.
+func (m *Model) vectorize(counts map[string]int) vector {
+	v := make(vector, len(counts))
+	for term, count := range counts {
+		if idf, ok := m.idf[term]; ok {
+			v[term] = float64(count) * idf
+		}
+	}
+	return normalize(v)
+}
+
+// termCounts counts the stems of the words of the text. Words shorter than three letters are skipped.
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if len([]rune(w)) < 3 {
+			continue
+		}
+		counts[stem(w)]++
+	}
+	return counts
+}
+
+// stem cuts the ending off a word: up to two runes, keeping at least three.
+func stem(word string) string {
+	runes := []rune(word)
+	cut := min(2, max(len(runes)-3, 0))
+	return string(runes[:len(runes)-cut])
+}
+
+func normalize(v vector) vector {
+	var norm float64
+	for _, w := range v {
+		norm += w * w
+	}
+	if norm == 0 {
+		return v
+	}
+	norm = math.Sqrt(norm)
+	for term := range v {
+		v[term] /= norm
+	}
+	return v
+}
+
+func dot(a, b vector) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var sum float64
+	for term, w := range a {
+		sum += w * b[term]
+	}
+	return sum
+}
@@ -0,0 +1,48 @@
+package classifier
+
+import "testing"
+
+func TestModel_Predict(t *testing.T) {
+	model := Train([]Example{
+		{Text: "Контейнеры переполнены, мусор не вывозят неделю", Label: "Мусор"},
+		{Text: "Во дворе не вывозят мусор из контейнеров", Label: "Мусор"},
+		{Text: "Огромная яма на дороге, асфальт разрушен", Label: "Дороги"},
+		{Text: "Ямы на проезжей части после ремонта дороги", Label: "Дороги"},
+		{Text: "Не горит фонарь у подъезда", Label: "Освещение"},
+		{Text: "?!", Label: "Пусто"},
+	})
+
+	tests := []struct {
+		text  string
+		label string
+	}{
+		{"Мусорные контейнеры не вывозят", "Мусор"},
+		{"Яма посреди дороги", "Дороги"},
+		{"Фонари не горят всю ночь", "Освещение"},
+	}
+	for _, tt := range tests {
+		got, ok := model.Predict(tt.text)
+		if !ok || got.Label != tt.label {
+			t.Errorf("Predict(%q) = %+v, %v, want %q", tt.text, got, ok, tt.label)
+		}
+		if got.Confidence <= 0 || got.Confidence > 1 {
+			t.Errorf("Predict(%q) confidence = %v, want (0, 1]", tt.text, got.Confidence)
+		}
+	}
+
+	if got, ok := model.Predict("Сломалась скамейка"); ok {
+		t.Errorf("Predict(unknown words) = %+v, want no prediction", got)
+	}
+	if labels := model.Labels(); len(labels) != 3 {
+		t.Errorf("labels = %v, want 3: the example without terms is skipped", labels)
+	}
+}
+
+func TestModel_PredictUntrained(t *testing.T) {
+	var nilModel *Model
+	for _, m := range []*Model{nilModel, Train(nil)} {
+		if got, ok := m.Predict("Мусор не вывозят"); ok {
+			t.Errorf("Predict() = %+v, want no prediction", got)
+		}
+	}
+}
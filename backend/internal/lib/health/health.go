@@ -0,0 +1,164 @@
+// Package health tracks the availability of external dependencies.
+// Every dependency is probed in the background, so callers learn that it is down
+// without waiting for their own requests to time out.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"hack/internal/lib/logger/sl"
+)
+
+// Status is the state of a component or of the whole service.
+type Status string
+
+const (
+	// StatusOK means every component is up.
+	StatusOK Status = "ok"
+	// StatusDegraded means an optional component is down, the service works with reduced functionality.
+	StatusDegraded Status = "degraded"
+	// StatusDown means a critical component is down.
+	StatusDown Status = "down"
+)
+
+// Probe checks a dependency and returns an error if it is unreachable.
+type Probe func(ctx context.Context) error
+
+// Component is a dependency probed by a Checker.
+type Component struct {
+	name     string
+	critical bool
+	probe    Probe
+
+	mu    sync.RWMutex
+	err   error
+	since time.Time
+}
+
+// Up reports whether the last probe succeeded.
+func (c *Component) Up() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.err == nil
+}
+
+// ComponentReport is the state of a component.
+type ComponentReport struct {
+	Name     string    `json:"name"`
+	Status   Status    `json:"status"`
+	Critical bool      `json:"critical"`
+	Error    string    `json:"error,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+// Report is the state of the service and its components.
+type Report struct {
+	Status     Status            `json:"status"`
+	Components []ComponentReport `json:"components"`
+}
+
+// Checker probes the components every interval.
+type Checker struct {
+	log        *slog.Logger
+	interval   time.Duration
+	timeout    time.Duration
+	components []*Component
+}
+
+// NewChecker creates a checker probing every interval, each probe limited by timeout.
+func NewChecker(log *slog.Logger, interval, timeout time.Duration) *Checker {
+	return &Checker{
+		log:      log.With(slog.String("component", "health")),
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Add registers a component and probes it at once. The service is down while a critical component is down
+// and degraded while an optional one is.
+// Components must be added before Run.
+func (c *Checker) Add(name string, critical bool, probe Probe) *Component {
+	comp := &Component{name: name, critical: critical, probe: probe}
+	c.components = append(c.components, comp)
+
+	if err := c.check(context.Background(), comp); err != nil {
+		c.log.Warn("component is down, reconnecting in background", slog.String("name", name), sl.Err(err))
+	}
+
+	return comp
+}
+
+// Run probes the components every interval until the context is canceled, logging their ups and downs.
+func (c *Checker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		for _, comp := range c.components {
+			wasUp := comp.Up()
+			err := c.check(ctx, comp)
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case err != nil && wasUp:
+				c.log.Error("component is down", slog.String("name", comp.name), sl.Err(err))
+			case err == nil && !wasUp:
+				c.log.Info("component is up again", slog.String("name", comp.name))
+			}
+		}
+	}
+}
+
+// Report returns the current state of the components.
+func (c *Checker) Report() Report {
+	report := Report{Status: StatusOK, Components: make([]ComponentReport, 0, len(c.components))}
+
+	for _, comp := range c.components {
+		comp.mu.RLock()
+		cr := ComponentReport{Name: comp.name, Status: StatusOK, Critical: comp.critical, Since: comp.since}
+		if comp.err != nil {
+			cr.Status = StatusDown
+			cr.Error = comp.err.Error()
+		}
+		comp.mu.RUnlock()
+
+		switch {
+		case cr.Status == StatusOK:
+		case comp.critical:
+			report.Status = StatusDown
+		case report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+		report.Components = append(report.Components, cr)
+	}
+
+	return report
+}
+
+// check probes the component and stores the result. since changes only when the component goes up or down.
+func (c *Checker) check(ctx context.Context, comp *Component) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	err := comp.probe(ctx)
+
+	comp.mu.Lock()
+	defer comp.mu.Unlock()
+
+	if comp.since.IsZero() || (err == nil) != (comp.err == nil) {
+		comp.since = time.Now()
+	}
+	comp.err = err
+
+	return err
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestChecker_Report(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	down := errors.New("connection refused")
+
+	var redisErr, postgresErr error
+	c := NewChecker(log, time.Second, time.Second)
+	c.Add("postgres", true, func(context.Context) error { return postgresErr })
+	redis := c.Add("redis", false, func(context.Context) error { return redisErr })
+
+	tests := []struct {
+		name        string
+		redisErr    error
+		postgresErr error
+		want        Status
+	}{
+		{"all up", nil, nil, StatusOK},
+		{"optional down", down, nil, StatusDegraded},
+		{"critical down", down, down, StatusDown},
+		{"back up", nil, nil, StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisErr, postgresErr = tt.redisErr, tt.postgresErr
+			for _, comp := range c.components {
+				c.check(context.Background(), comp)
+			}
+
+			report := c.Report()
+			if report.Status != tt.want {
+				t.Errorf("status = %q, want %q", report.Status, tt.want)
+			}
+			if redis.Up() != (tt.redisErr == nil) {
+				t.Errorf("redis.Up() = %v with error %v", redis.Up(), tt.redisErr)
+			}
+			if got := report.Components[1]; (got.Status == StatusDown) != (tt.redisErr != nil) || (tt.redisErr != nil && got.Error != down.Error()) {
+				t.Errorf("redis report = %+v", got)
+			}
+		})
+	}
+}
+
+func TestChecker_Since(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var err error
+	c := NewChecker(log, time.Second, time.Second)
+	comp := c.Add("kafka", false, func(context.Context) error { return err })
+	upSince := comp.since
+
+	c.check(context.Background(), comp)
+	if !comp.since.Equal(upSince) {
+		t.Errorf("since changed while the component stayed up")
+	}
+
+	err = errors.New("down")
+	c.check(context.Background(), comp)
+	if !comp.since.After(upSince) {
+		t.Errorf("since did not change when the component went down")
+	}
+}
@@ -6,23 +6,69 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
+
 // Consumer represents Message broker consumer.
 type Consumer struct {
 	reader    *kafka.Reader
 	dlqWriter *kafka.Writer
+	retry     RetryPolicy
 }
 
 // MessageHandler is a function type for processing incoming Kafka messages.
 type MessageHandler func(ctx context.Context, value []byte) error
 
+// RetryPolicy controls how many times a message is processed before it goes to the DLQ.
+// Before attempt n (n ≥ 2) the consumer waits InitialBackoff * Multiplier^(n-2), but not longer than MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// backoff returns the delay before the given attempt, attempts are counted from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-2))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// permanentError marks an error retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps a handler error to send the message to the DLQ without retries,
+// e.g. when it cannot be decoded.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
 
 // NewConsumer creates and configures a new Kafka consumer with DLQ writer.
 // It connects to the specified brokers, joins the consumer group and subscribes to the topic.
-func NewConsumer(brokers []string, group, topic, dlqTopic string) *Consumer {
+func NewConsumer(brokers []string, group, topic, dlqTopic string, retry RetryPolicy) *Consumer {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	if retry.Multiplier < 1 {
+		retry.Multiplier = 2
+	}
 	return &Consumer{
 		reader: kafka.NewReader(kafka.ReaderConfig{
 			Brokers:  brokers,
@@ -39,15 +85,18 @@ func NewConsumer(brokers []string, group, topic, dlqTopic string) *Consumer {
 			RequiredAcks:           kafka.RequireOne,
 			AllowAutoTopicCreation: true,
 		},
+		retry: retry,
 	}
 }
 
 // Start begins consuming messages from Kafka and processes them using the provided handler.
 // It runs until the context is canceled or a fatal error occurs.
-// On handler error — message is skipped (not committed), but consumption continues.
-// On commit error — message is sent to DLQ if possible.
+// A failed message is retried with exponential backoff according to the retry policy.
+// When its attempts are exhausted, or the handler returns a Permanent error, the message is sent
+// to the DLQ with the attempt metadata in headers and its offset is committed.
+// If the DLQ is unavailable the consumer stops without committing, so the message is not lost.
 func (c *Consumer) Start(ctx context.Context, handler MessageHandler) error {
-    const op = "kafka.consumer.Start"
+	const op = "kafka.consumer.Start"
 
 	for {
 		msg, err := c.reader.FetchMessage(ctx)
@@ -58,20 +107,62 @@ func (c *Consumer) Start(ctx context.Context, handler MessageHandler) error {
 			return fmt.Errorf("%s: fetch err: %w", op, err)
 		}
 
-		if err := handler(ctx, msg.Value); err != nil {
-			continue
+		attempts, procErr := c.process(ctx, handler, msg)
+		if procErr != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := c.sendToDLQ(ctx, msg, procErr, attempts); err != nil {
+				return fmt.Errorf("%s: message %s/%d/%d: %w", op, msg.Topic, msg.Partition, msg.Offset, err)
+			}
 		}
 
 		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			_ = c.sendToDLQ(ctx, msg, err)
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("%s: commit err: %w", op, err)
+		}
+	}
+}
+
+// attempt describes one handler call on a message.
+type attempt struct {
+	number int
+	at     time.Time
+}
+
+// process runs the handler until it succeeds, fails permanently or the attempts are exhausted.
+// It returns the attempts made and the last error.
+func (c *Consumer) process(ctx context.Context, handler MessageHandler, msg kafka.Message) (attempts []attempt, err error) {
+	for n := 1; n <= c.retry.MaxAttempts; n++ {
+		if d := c.retry.backoff(n); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return attempts, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		attempts = append(attempts, attempt{number: n, at: time.Now()})
+		if err = handler(ctx, msg.Value); err == nil {
+			return attempts, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return attempts, err
 		}
 	}
+	return attempts, err
 }
 
 // Close gracefully shuts down the consumer and DLQ writer.
 // It closes both connections and collects any errors.
 func (c *Consumer) Close() error {
-    const op = "kafka.consumer.Close"
+	const op = "kafka.consumer.Close"
 
 	var errs []error
 	if c.reader != nil {
@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 0},
+		{2, 100 * time.Millisecond},
+		{3, 200 * time.Millisecond},
+		{4, 400 * time.Millisecond},
+		{5, 800 * time.Millisecond},
+		{6, time.Second},
+		{20, time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestConsumer_process(t *testing.T) {
+	errTemporary := errors.New("db is down")
+
+	tests := []struct {
+		name         string
+		failures     int
+		permanent    bool
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"first attempt", 0, false, 1, false},
+		{"succeeds after retries", 2, false, 3, false},
+		{"exhausted", 10, false, 4, true},
+		{"permanent", 10, true, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Consumer{retry: RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, Multiplier: 2}}
+
+			calls := 0
+			handler := func(ctx context.Context, value []byte) error {
+				calls++
+				if calls > tt.failures {
+					return nil
+				}
+				if tt.permanent {
+					return Permanent(errTemporary)
+				}
+				return errTemporary
+			}
+
+			attempts, err := c.process(context.Background(), handler, kafka.Message{Value: []byte(`{}`)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errTemporary) {
+				t.Errorf("process() error = %v, want %v", err, errTemporary)
+			}
+			if len(attempts) != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("attempts = %d, calls = %d, want %d", len(attempts), calls, tt.wantAttempts)
+			}
+			for i, a := range attempts {
+				if a.number != i+1 {
+					t.Errorf("attempts[%d].number = %d", i, a.number)
+				}
+			}
+		})
+	}
+}
+
+func TestConsumer_process_canceled(t *testing.T) {
+	c := &Consumer{retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, Multiplier: 2}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	handler := func(ctx context.Context, value []byte) error {
+		cancel()
+		return errors.New("failed")
+	}
+
+	attempts, err := c.process(ctx, handler, kafka.Message{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("process() error = %v, want context.Canceled", err)
+	}
+	if len(attempts) != 1 {
+		t.Errorf("attempts = %d, want 1", len(attempts))
+	}
+}
+
+func TestDLQHeaders(t *testing.T) {
+	first := time.Date(2023, 12, 9, 10, 0, 0, 0, time.UTC)
+	attempts := []attempt{{1, first}, {2, first.Add(time.Second)}}
+	msg := kafka.Message{Topic: "orders"}
+
+	headers := func(err error) map[string]string {
+		m := make(map[string]string)
+		for _, h := range dlqHeaders(msg, err, attempts, 5) {
+			m[h.Key] = string(h.Value)
+		}
+		return m
+	}
+
+	got := headers(errors.New("db is down"))
+	want := map[string]string{
+		"dlq_reason":           "db is down",
+		"original_topic":       "orders",
+		"dlq_attempts":         "2",
+		"dlq_max_attempts":     "5",
+		"dlq_first_attempt_at": "2023-12-09T10:00:00Z",
+		"dlq_last_attempt_at":  "2023-12-09T10:00:01Z",
+	}
+	if len(got) != len(want) {
+		t.Errorf("dlqHeaders() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("header %s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if got := headers(Permanent(errors.New("bad json"))); got["dlq_permanent"] != "true" {
+		t.Errorf("dlq_permanent = %q, want true", got["dlq_permanent"])
+	}
+}
@@ -3,30 +3,45 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
-
-type dlqMessage struct {
+// DLQMessage is the value written to the dead letter queue:
+// the original message with the error that sent it there.
+// OriginalBytes holds the value instead of OriginalValue when it is not valid JSON, e.g. a poison message.
+type DLQMessage struct {
 	OriginalTopic     string            `json:"original_topic"`
 	OriginalPartition int               `json:"original_partition"`
 	OriginalOffset    int64             `json:"original_offset"`
 	OriginalKey       string            `json:"original_key"`
-	OriginalValue     json.RawMessage   `json:"original_value"`
+	OriginalValue     json.RawMessage   `json:"original_value,omitempty"`
+	OriginalBytes     []byte            `json:"original_bytes,omitempty"`
 	Error             string            `json:"error"`
+	Attempts          int               `json:"attempts,omitempty"`
 	FailedAt          time.Time         `json:"failed_at"`
 	Headers           map[string]string `json:"headers,omitempty"`
 }
 
-// sendToDLQ sends the original message to the dead letter queue with error context.
-func (c *Consumer) sendToDLQ(ctx context.Context, msg kafka.Message, procErr error) error {
+// Value returns the original message value.
+func (m DLQMessage) Value() []byte {
+	if m.OriginalBytes != nil {
+		return m.OriginalBytes
+	}
+	return m.OriginalValue
+}
+
+// sendToDLQ sends the original message to the dead letter queue with error context
+// and the attempt metadata.
+func (c *Consumer) sendToDLQ(ctx context.Context, msg kafka.Message, procErr error, attempts []attempt) error {
 	const op = "kafka.dlq.sendToDLQ"
 
-    if c.dlqWriter == nil {
-		return fmt.Errorf("%s: errors during close: %v", op, "dlq writer not configured")
+	if c.dlqWriter == nil {
+		return fmt.Errorf("%s: dlq writer not configured", op)
 	}
 
 	headers := make(map[string]string)
@@ -34,28 +49,55 @@ func (c *Consumer) sendToDLQ(ctx context.Context, msg kafka.Message, procErr err
 		headers[h.Key] = string(h.Value)
 	}
 
-	dlqMsg := dlqMessage{
+	dlqMsg := DLQMessage{
 		OriginalTopic:     msg.Topic,
 		OriginalPartition: msg.Partition,
 		OriginalOffset:    msg.Offset,
 		OriginalKey:       string(msg.Key),
-		OriginalValue:     msg.Value,
 		Error:             procErr.Error(),
+		Attempts:          len(attempts),
 		FailedAt:          time.Now().UTC(),
 		Headers:           headers,
 	}
+	if json.Valid(msg.Value) {
+		dlqMsg.OriginalValue = msg.Value
+	} else {
+		dlqMsg.OriginalBytes = msg.Value
+	}
 
 	valueBytes, err := json.Marshal(dlqMsg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal dlq message: %w", err)
+		return fmt.Errorf("%s: failed to marshal dlq message: %w", op, err)
 	}
 
-	return c.dlqWriter.WriteMessages(ctx, kafka.Message{
-		Key:   msg.Key,
-		Value: valueBytes,
-		Headers: []kafka.Header{
-			{Key: "dlq_reason", Value: []byte(procErr.Error())},
-			{Key: "original_topic", Value: []byte(msg.Topic)},
-		},
+	err = c.dlqWriter.WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   valueBytes,
+		Headers: dlqHeaders(msg, procErr, attempts, c.retry.MaxAttempts),
 	})
-}
\ No newline at end of file
+	if err != nil {
+		return fmt.Errorf("%s: write: %w", op, err)
+	}
+	return nil
+}
+
+// dlqHeaders describes why and after how many attempts the message was given up on.
+func dlqHeaders(msg kafka.Message, procErr error, attempts []attempt, maxAttempts int) []kafka.Header {
+	headers := []kafka.Header{
+		{Key: "dlq_reason", Value: []byte(procErr.Error())},
+		{Key: "original_topic", Value: []byte(msg.Topic)},
+		{Key: "dlq_attempts", Value: []byte(strconv.Itoa(len(attempts)))},
+		{Key: "dlq_max_attempts", Value: []byte(strconv.Itoa(maxAttempts))},
+	}
+	var permanent *permanentError
+	if errors.As(procErr, &permanent) {
+		headers = append(headers, kafka.Header{Key: "dlq_permanent", Value: []byte("true")})
+	}
+	if len(attempts) > 0 {
+		headers = append(headers,
+			kafka.Header{Key: "dlq_first_attempt_at", Value: []byte(attempts[0].at.UTC().Format(time.RFC3339Nano))},
+			kafka.Header{Key: "dlq_last_attempt_at", Value: []byte(attempts[len(attempts)-1].at.UTC().Format(time.RFC3339Nano))},
+		)
+	}
+	return headers
+}
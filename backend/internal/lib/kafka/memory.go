@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBrokerClosed is returned by MemoryBroker.Send after Close.
+var ErrBrokerClosed = errors.New("broker closed")
+
+// MemoryMessage is a message sent to a MemoryBroker.
+type MemoryMessage struct {
+	Key   string
+	Value []byte
+}
+
+// FailedMessage is a message the handler of a MemoryBroker returned an error for.
+type FailedMessage struct {
+	MemoryMessage
+	Err error
+}
+
+// MemoryBroker is an in-memory topic standing in for Producer and Consumer in tests and local runs without Kafka.
+// Send queues a message and Start hands queued messages to a handler one at a time.
+// Messages are kept until consumed; without a consumer only the latest capacity messages are kept.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	capacity int
+	queue    []MemoryMessage
+	failed   []FailedMessage
+	busy     bool
+	closed   bool
+	changed  chan struct{}
+}
+
+// NewMemoryBroker creates a broker keeping at most capacity unconsumed messages, zero means no limit.
+func NewMemoryBroker(capacity int) *MemoryBroker {
+	return &MemoryBroker{capacity: capacity, changed: make(chan struct{})}
+}
+
+// Send queues a copy of the message.
+func (b *MemoryBroker) Send(_ context.Context, key string, value []byte) error {
+	const op = "kafka.memory.Send"
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return fmt.Errorf("%s: %w", op, ErrBrokerClosed)
+	}
+	b.queue = append(b.queue, MemoryMessage{Key: key, Value: append([]byte(nil), value...)})
+	if b.capacity > 0 && len(b.queue) > b.capacity {
+		b.queue = b.queue[len(b.queue)-b.capacity:]
+	}
+	b.notify()
+
+	return nil
+}
+
+// Start passes queued messages to the handler in order until the context is canceled or the broker is closed.
+// Messages the handler fails on are not retried, they are kept for Failed instead.
+func (b *MemoryBroker) Start(ctx context.Context, handler MessageHandler) error {
+	for {
+		b.mu.Lock()
+		for len(b.queue) == 0 {
+			if b.closed {
+				b.mu.Unlock()
+				return nil
+			}
+			changed := b.changed
+			b.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-changed:
+			}
+			b.mu.Lock()
+		}
+		msg := b.queue[0]
+		b.queue = b.queue[1:]
+		b.busy = true
+		b.mu.Unlock()
+
+		err := handler(ctx, msg.Value)
+
+		b.mu.Lock()
+		if err != nil {
+			b.failed = append(b.failed, FailedMessage{MemoryMessage: msg, Err: err})
+		}
+		b.busy = false
+		b.notify()
+		b.mu.Unlock()
+	}
+}
+
+// WaitIdle blocks until every sent message has been handled or the context is done.
+func (b *MemoryBroker) WaitIdle(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if len(b.queue) == 0 && !b.busy {
+			b.mu.Unlock()
+			return nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Messages returns the messages not consumed yet.
+func (b *MemoryBroker) Messages() []MemoryMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]MemoryMessage(nil), b.queue...)
+}
+
+// Failed returns the messages the handler has failed on.
+func (b *MemoryBroker) Failed() []FailedMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]FailedMessage(nil), b.failed...)
+}
+
+// Close rejects further messages. Start returns once the queued ones are handled.
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.notify()
+
+	return nil
+}
+
+// notify wakes up everyone waiting for a change. The caller holds the lock.
+func (b *MemoryBroker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
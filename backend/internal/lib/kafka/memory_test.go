@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var handled []string
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Start(ctx, func(_ context.Context, value []byte) error {
+			handled = append(handled, string(value))
+			if string(value) == "bad" {
+				return errors.New("cannot handle")
+			}
+			return nil
+		})
+	}()
+
+	for _, v := range []string{"a", "bad", "b"} {
+		if err := b.Send(ctx, "key", []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.WaitIdle(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handled) != 3 || handled[0] != "a" || handled[2] != "b" {
+		t.Errorf("handled = %v, want messages in order", handled)
+	}
+	if failed := b.Failed(); len(failed) != 1 || string(failed[0].Value) != "bad" {
+		t.Errorf("failed = %v, want the bad message", failed)
+	}
+
+	b.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Start() = %v", err)
+	}
+	if err := b.Send(ctx, "key", nil); !errors.Is(err, ErrBrokerClosed) {
+		t.Errorf("Send() after Close = %v, want ErrBrokerClosed", err)
+	}
+}
+
+func TestMemoryBroker_Capacity(t *testing.T) {
+	b := NewMemoryBroker(2)
+	for _, v := range []string{"a", "b", "c"} {
+		if err := b.Send(context.Background(), v, []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs := b.Messages()
+	if len(msgs) != 2 || msgs[0].Key != "b" || msgs[1].Key != "c" {
+		t.Errorf("messages = %v, want the latest two", msgs)
+	}
+}
@@ -4,6 +4,7 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -12,16 +13,19 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
+// ErrUnavailable is returned by Send of a guarded producer while the broker is known to be down.
+var ErrUnavailable = errors.New("broker unavailable")
+
 // Producer represents Message broker producer.
 type Producer struct {
-	writer *kafka.Writer
+	writer    *kafka.Writer
+	brokers   []string
+	available func() bool
 }
 
-// MustProducer initializes Message broker producer.
-// If a failure occurs during migration, os.exit is executed
-func MustProducer(log *slog.Logger, brokers []string, topic string) *Producer {
-	const op = "kafka.produser.MustProducer"
-
+// NewProducer creates Message broker producer without connecting:
+// every write dials the brokers, so they may be down at startup.
+func NewProducer(brokers []string, topic string) *Producer {
 	writer := &kafka.Writer{
 		Addr:                   kafka.TCP(brokers...),
 		Topic:                  topic,
@@ -31,11 +35,19 @@ func MustProducer(log *slog.Logger, brokers []string, topic string) *Producer {
 		AllowAutoTopicCreation: true,
 	}
 
+	return &Producer{writer: writer, brokers: brokers}
+}
+
+// MustProducer initializes Message broker producer.
+// If the broker is unavailable, os.Exit is executed.
+func MustProducer(log *slog.Logger, brokers []string, topic string) *Producer {
+	const op = "kafka.produser.MustProducer"
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := kafka.DialContext(ctx, "tcp", brokers[0])
-	if err != nil {
+	p := NewProducer(brokers, topic)
+	if err := p.Ping(ctx); err != nil {
 		log.Error("failed to dial kafka broker",
 			slog.String("op", op),
 			slog.String("broker", brokers[0]),
@@ -43,21 +55,38 @@ func MustProducer(log *slog.Logger, brokers []string, topic string) *Producer {
 		os.Exit(1)
 	}
 
+	return p
+}
+
+// Guard makes Send fail with ErrUnavailable at once while available reports false,
+// instead of waiting for the write timeout.
+func (p *Producer) Guard(available func() bool) *Producer {
+	p.available = available
+	return p
+}
+
+// Ping dials the first broker.
+func (p *Producer) Ping(ctx context.Context) error {
+	const op = "kafka.produser.Ping"
+
+	conn, err := kafka.DialContext(ctx, "tcp", p.brokers[0])
+	if err != nil {
+		return fmt.Errorf("%s: dial %s: %w", op, p.brokers[0], err)
+	}
 	if err := conn.Close(); err != nil {
-		log.Error("failed close connection",
-			slog.String("op", op),
-			slog.String("broker", brokers[0]),
-			slog.Any("err", err))
-		os.Exit(1)
+		return fmt.Errorf("%s: close connection: %w", op, err)
 	}
-
-	return &Producer{writer: writer}
+	return nil
 }
 
 // Send writes a batch of messages to the kafka topic configured on this writer.
 func (p *Producer) Send(ctx context.Context, key string, value []byte) error {
 	const op = "kafka.produser.Send"
 
+	if p.available != nil && !p.available() {
+		return fmt.Errorf("%s: %w", op, ErrUnavailable)
+	}
+
 	err := p.writer.WriteMessages(ctx, kafka.Message{
 		Key:   []byte(key),
 		Value: value,
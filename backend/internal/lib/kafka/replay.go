@@ -0,0 +1,213 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DLQEntry is a message read from the dead letter queue with its position there.
+// DecodeErr is set when the value is not a DLQMessage; such entries cannot be replayed.
+type DLQEntry struct {
+	Partition int        `json:"partition"`
+	Offset    int64      `json:"offset"`
+	Time      time.Time  `json:"time"`
+	Message   DLQMessage `json:"message"`
+	DecodeErr string     `json:"decode_error,omitempty"`
+}
+
+// DLQFilter selects DLQ entries. Zero fields match everything.
+type DLQFilter struct {
+	// ErrorContains matches entries whose error contains the substring, case-insensitively.
+	ErrorContains string
+	// Topic matches entries failed in the given original topic.
+	Topic string
+	// Since and Until bound FailedAt: Since <= FailedAt < Until.
+	Since time.Time
+	Until time.Time
+}
+
+// Match reports whether the entry passes the filter.
+func (f DLQFilter) Match(e DLQEntry) bool {
+	if f.ErrorContains != "" &&
+		!strings.Contains(strings.ToLower(e.Message.Error), strings.ToLower(f.ErrorContains)) {
+		return false
+	}
+	if f.Topic != "" && e.Message.OriginalTopic != f.Topic {
+		return false
+	}
+	if !f.Since.IsZero() && e.Message.FailedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Message.FailedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// dlqIdleTimeout bounds the wait for the next message of a partition. The high-water mark
+// may point past a transaction marker or a compacted offset that is never delivered,
+// so a partition silent for this long is taken as read to the end.
+const dlqIdleTimeout = 10 * time.Second
+
+// DLQ reads the dead letter queue and replays its messages to their original topics.
+// It does not join a consumer group, so inspecting the queue never moves any committed offset.
+type DLQ struct {
+	brokers []string
+	topic   string
+	writer  *kafka.Writer
+}
+
+// NewDLQ creates a DLQ client for the topic.
+func NewDLQ(brokers []string, topic string) *DLQ {
+	return &DLQ{
+		brokers: brokers,
+		topic:   topic,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Balancer:     &kafka.LeastBytes{},
+			WriteTimeout: 5 * time.Second,
+			RequiredAcks: kafka.RequireOne,
+		},
+	}
+}
+
+// Scan calls fn for every message currently in the queue, partition by partition in offset order.
+// Messages written after Scan has started are not visited.
+func (d *DLQ) Scan(ctx context.Context, fn func(DLQEntry) error) error {
+	const op = "kafka.dlq.Scan"
+
+	conn, err := kafka.DialContext(ctx, "tcp", d.brokers[0])
+	if err != nil {
+		return fmt.Errorf("%s: dial: %w", op, err)
+	}
+	partitions, err := conn.ReadPartitions(d.topic)
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("%s: read partitions: %w", op, err)
+	}
+
+	for _, p := range partitions {
+		if err := d.scanPartition(ctx, p.ID, fn); err != nil {
+			return fmt.Errorf("%s: partition %d: %w", op, p.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DLQ) scanPartition(ctx context.Context, partition int, fn func(DLQEntry) error) error {
+	leader, err := kafka.DialLeader(ctx, "tcp", d.brokers[0], d.topic, partition)
+	if err != nil {
+		return fmt.Errorf("dial leader: %w", err)
+	}
+	first, last, err := leader.ReadOffsets()
+	leader.Close()
+	if err != nil {
+		return fmt.Errorf("read offsets: %w", err)
+	}
+	if first >= last {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   d.brokers,
+		Topic:     d.topic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6, // 10MB
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(first); err != nil {
+		return fmt.Errorf("set offset: %w", err)
+	}
+
+	return readUntil(ctx, reader.ReadMessage, last, dlqIdleTimeout, fn)
+}
+
+// readUntil calls fn for the messages returned by read until the one before offset last.
+// It stops without an error when no message comes within idle.
+func readUntil(ctx context.Context, read func(context.Context) (kafka.Message, error), last int64, idle time.Duration, fn func(DLQEntry) error) error {
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, idle)
+		msg, err := read(readCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return nil
+			}
+			return fmt.Errorf("read message: %w", err)
+		}
+
+		if err := fn(decodeDLQEntry(msg)); err != nil {
+			return err
+		}
+		if msg.Offset >= last-1 {
+			return nil
+		}
+	}
+}
+
+func decodeDLQEntry(msg kafka.Message) DLQEntry {
+	entry := DLQEntry{Partition: msg.Partition, Offset: msg.Offset, Time: msg.Time}
+	if err := json.Unmarshal(msg.Value, &entry.Message); err != nil {
+		entry.DecodeErr = err.Error()
+		entry.Message = DLQMessage{}
+	}
+	return entry
+}
+
+// ErrNotReplayable is returned for entries without an original topic.
+var ErrNotReplayable = errors.New("dlq entry cannot be replayed")
+
+// Replay writes the original key, value and headers of the entries back to their original topics.
+// The copies carry dlq_partition, dlq_offset and dlq_replayed_at headers replacing those of earlier replays.
+// Kafka cannot delete messages, so the entries stay in the queue.
+func (d *DLQ) Replay(ctx context.Context, entries ...DLQEntry) error {
+	const op = "kafka.dlq.Replay"
+
+	msgs := make([]kafka.Message, 0, len(entries))
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, e := range entries {
+		if e.DecodeErr != "" || e.Message.OriginalTopic == "" {
+			return fmt.Errorf("%s: partition %d offset %d: %w", op, e.Partition, e.Offset, ErrNotReplayable)
+		}
+
+		headers := make([]kafka.Header, 0, len(e.Message.Headers)+3)
+		for k, v := range e.Message.Headers {
+			if strings.HasPrefix(k, "dlq_") {
+				continue
+			}
+			headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+		headers = append(headers,
+			kafka.Header{Key: "dlq_partition", Value: []byte(strconv.Itoa(e.Partition))},
+			kafka.Header{Key: "dlq_offset", Value: []byte(strconv.FormatInt(e.Offset, 10))},
+			kafka.Header{Key: "dlq_replayed_at", Value: []byte(now)},
+		)
+
+		msgs = append(msgs, kafka.Message{
+			Topic:   e.Message.OriginalTopic,
+			Key:     []byte(e.Message.OriginalKey),
+			Value:   e.Message.Value(),
+			Headers: headers,
+		})
+	}
+
+	if err := d.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("%s: write: %w", op, err)
+	}
+	return nil
+}
+
+// Close flushes pending replays.
+func (d *DLQ) Close() error {
+	return d.writer.Close()
+}
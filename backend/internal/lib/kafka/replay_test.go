@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestDLQFilter_Match(t *testing.T) {
+	failedAt := time.Date(2023, 12, 9, 10, 0, 0, 0, time.UTC)
+	entry := DLQEntry{Message: DLQMessage{
+		OriginalTopic: "orders",
+		Error:         "usecase.StoreSubmission: validator: Key: 'Statement.Description' Error:Field validation",
+		FailedAt:      failedAt,
+	}}
+
+	tests := []struct {
+		name   string
+		filter DLQFilter
+		want   bool
+	}{
+		{"empty filter", DLQFilter{}, true},
+		{"error case-insensitive", DLQFilter{ErrorContains: "VALIDATOR"}, true},
+		{"other error", DLQFilter{ErrorContains: "timeout"}, false},
+		{"topic", DLQFilter{Topic: "orders"}, true},
+		{"other topic", DLQFilter{Topic: "statement-events"}, false},
+		{"since inclusive", DLQFilter{Since: failedAt}, true},
+		{"since after", DLQFilter{Since: failedAt.Add(time.Second)}, false},
+		{"until exclusive", DLQFilter{Until: failedAt}, false},
+		{"within range", DLQFilter{Since: failedAt.Add(-time.Hour), Until: failedAt.Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(entry); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readUntil(t *testing.T) {
+	// Offset 2 is a transaction marker: the high-water mark is 3, but only 0 and 1 are delivered.
+	msgs := []kafka.Message{{Offset: 0, Value: []byte("{}")}, {Offset: 1, Value: []byte("{}")}}
+	read := func(ctx context.Context) (kafka.Message, error) {
+		if len(msgs) == 0 {
+			<-ctx.Done()
+			return kafka.Message{}, ctx.Err()
+		}
+		msg := msgs[0]
+		msgs = msgs[1:]
+		return msg, nil
+	}
+
+	var got []int64
+	err := readUntil(context.Background(), read, 3, 10*time.Millisecond, func(e DLQEntry) error {
+		got = append(got, e.Offset)
+		return nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Errorf("readUntil() = %v, read offsets %v, want 0 and 1", err, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := readUntil(ctx, read, 3, time.Second, func(DLQEntry) error { return nil }); err == nil {
+		t.Error("readUntil() with a canceled context error = nil")
+	}
+}
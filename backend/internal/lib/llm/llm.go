@@ -0,0 +1,52 @@
+// Package llm provides chat completion clients for the recommendation features:
+// Mistral, any OpenAI-compatible HTTP API and a deterministic local stub.
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Role is the author of a chat message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// Message is one message of a chat.
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a chat completion request. The model and its settings are chosen by the client.
+type Request struct {
+	Messages []Message
+	// Schema asks for an answer in JSON matching the schema. The model may still deviate from it,
+	// so the answer has to be validated anyway.
+	Schema *Schema
+}
+
+// Schema is a named JSON schema of the answer.
+type Schema struct {
+	Name   string
+	Schema json.RawMessage
+}
+
+// Options configure an HTTP client.
+type Options struct {
+	// BaseURL is the API root, e.g. https://api.openai.com/v1. Mistral has a default one.
+	BaseURL     string
+	APIKey      string
+	Model       string
+	Temperature float64
+	// Timeout limits one completion, zero means no limit.
+	Timeout time.Duration
+}
+
+// ErrEmptyResponse is returned when the model answers with no choices.
+var ErrEmptyResponse = errors.New("empty completion response")
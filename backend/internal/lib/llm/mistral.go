@@ -0,0 +1,13 @@
+package llm
+
+// MistralBaseURL is the root of the Mistral API.
+const MistralBaseURL = "https://api.mistral.ai/v1"
+
+// NewMistral creates a client of the Mistral API, which is OpenAI-compatible.
+// An empty BaseURL defaults to MistralBaseURL.
+func NewMistral(opts Options) *OpenAI {
+	if opts.BaseURL == "" {
+		opts.BaseURL = MistralBaseURL
+	}
+	return NewOpenAI(opts)
+}
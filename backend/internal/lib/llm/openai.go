@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody limits the part of an error response kept in the error message.
+const maxErrorBody = 512
+
+// OpenAI is a client of an OpenAI-compatible chat completions API.
+type OpenAI struct {
+	opts   Options
+	client *http.Client
+}
+
+// NewOpenAI creates a client posting to {BaseURL}/chat/completions.
+func NewOpenAI(opts Options) *OpenAI {
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	return &OpenAI{opts: opts, client: &http.Client{Timeout: opts.Timeout}}
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// Complete returns the content of the first choice. A request with a Schema is sent with
+// a json_schema response_format, which both OpenAI and Mistral support.
+func (c *OpenAI) Complete(ctx context.Context, req Request) (string, error) {
+	const op = "llm.OpenAI.Complete"
+
+	chatReq := chatRequest{
+		Model:       c.opts.Model,
+		Messages:    req.Messages,
+		Temperature: c.opts.Temperature,
+	}
+	if req.Schema != nil {
+		chatReq.ResponseFormat = &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &jsonSchema{Name: req.Schema.Name, Schema: req.Schema.Schema, Strict: true},
+		}
+	}
+
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return "", fmt.Errorf("%s: marshal request: %w", op, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%s: new request: %w", op, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	if c.opts.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.opts.APIKey)
+	}
+
+	res, err := c.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+		return "", fmt.Errorf("%s: %s: %s", op, res.Status, bytes.TrimSpace(data))
+	}
+
+	var completion chatResponse
+	if err := json.NewDecoder(res.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("%s: decode response: %w", op, err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("%s: %w", op, ErrEmptyResponse)
+	}
+
+	return completion.Choices[0].Message.Content, nil
+}
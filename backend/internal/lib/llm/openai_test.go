@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAI_Complete(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q, want /v1/chat/completions", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ответ"}}]}`))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAI(Options{BaseURL: srv.URL + "/v1/", APIKey: "secret", Model: "test-model", Temperature: 0.3})
+	answer, err := client.Complete(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "вопрос"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "ответ" {
+		t.Errorf("answer = %q, want %q", answer, "ответ")
+	}
+	if got.Model != "test-model" || got.Temperature != 0.3 || len(got.Messages) != 1 || got.Messages[0].Content != "вопрос" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestOpenAI_CompleteSchema(t *testing.T) {
+	var got struct {
+		ResponseFormat struct {
+			Type       string `json:"type"`
+			JSONSchema struct {
+				Name   string          `json:"name"`
+				Schema json.RawMessage `json:"schema"`
+				Strict bool            `json:"strict"`
+			} `json:"json_schema"`
+		} `json:"response_format"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices":[{"message":{"content":"{}"}}]}`))
+	}))
+	defer srv.Close()
+
+	schema := &Schema{Name: "answer", Schema: json.RawMessage(`{"type":"object"}`)}
+	if _, err := NewOpenAI(Options{BaseURL: srv.URL}).Complete(context.Background(), Request{Schema: schema}); err != nil {
+		t.Fatal(err)
+	}
+	format := got.ResponseFormat
+	if format.Type != "json_schema" || format.JSONSchema.Name != "answer" || !format.JSONSchema.Strict || string(format.JSONSchema.Schema) != `{"type":"object"}` {
+		t.Errorf("response_format = %+v", format)
+	}
+}
+
+func TestOpenAI_CompleteErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "status", status: http.StatusTooManyRequests, body: `{"message":"rate limited"}`, wantErr: "rate limited"},
+		{name: "no choices", status: http.StatusOK, body: `{"choices":[]}`, wantErr: ErrEmptyResponse.Error()},
+		{name: "invalid json", status: http.StatusOK, body: `{`, wantErr: "decode response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOpenAI(Options{BaseURL: srv.URL}).Complete(context.Background(), Request{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStub_Complete(t *testing.T) {
+	ctx := context.Background()
+	req := Request{Messages: []Message{{Role: RoleUser, Content: "вопрос"}}}
+
+	stub := NewStub()
+	first, _ := stub.Complete(ctx, req)
+	second, _ := stub.Complete(ctx, req)
+	if first == "" || first != second {
+		t.Errorf("answers = %q, %q, want equal non-empty answers", first, second)
+	}
+	if n := len(stub.Requests()); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+
+	stub = NewStub("a", "b")
+	var answers []string
+	for range 3 {
+		answer, _ := stub.Complete(ctx, req)
+		answers = append(answers, answer)
+	}
+	if strings.Join(answers, ",") != "a,b,a" {
+		t.Errorf("answers = %v, want a,b,a", answers)
+	}
+}
+
+func TestStub_CompleteSchema(t *testing.T) {
+	schema := &Schema{Name: "answer", Schema: json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"items": {"type": "array", "minItems": 2, "items": {"type": "string", "enum": ["x", "y"]}},
+			"title": {"type": "string"},
+			"count": {"type": "integer"}
+		}
+	}`)}
+
+	answer, err := NewStub().Complete(context.Background(), Request{Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Items []string `json:"items"`
+		Title string   `json:"title"`
+		Count int      `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(answer), &got); err != nil {
+		t.Fatalf("answer %q: %v", answer, err)
+	}
+	if strings.Join(got.Items, ",") != "x,x" || got.Title == "" {
+		t.Errorf("answer = %+v", got)
+	}
+}
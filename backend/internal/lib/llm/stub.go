@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Stub is a deterministic client for tests and offline runs, it never leaves the process.
+// It answers with Responses in turn, or, without them, with a fixed text naming the hash of the request,
+// so equal requests get equal answers. A request with a Schema is answered with the smallest document
+// matching it: every string is the fixed text or the first enum value, every array has minItems items.
+type Stub struct {
+	Responses []string
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewStub creates a stub answering with the responses in turn.
+func NewStub(responses ...string) *Stub {
+	return &Stub{Responses: responses}
+}
+
+// Complete records the request and returns the next response.
+func (s *Stub) Complete(_ context.Context, req Request) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+	if len(s.Responses) > 0 {
+		return s.Responses[(len(s.requests)-1)%len(s.Responses)], nil
+	}
+
+	h := sha256.New()
+	for _, m := range req.Messages {
+		h.Write([]byte(m.Role))
+		h.Write([]byte(m.Content))
+	}
+	text := "Локальная заглушка LLM, ответ " + hex.EncodeToString(h.Sum(nil)[:4])
+	if req.Schema == nil {
+		return text, nil
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(req.Schema.Schema, &schema); err != nil {
+		return "", fmt.Errorf("llm.Stub.Complete: decode schema: %w", err)
+	}
+	data, err := json.Marshal(stubValue(schema, text))
+	if err != nil {
+		return "", fmt.Errorf("llm.Stub.Complete: %w", err)
+	}
+	return string(data), nil
+}
+
+// stubValue returns the smallest value matching the schema, using text for strings.
+func stubValue(schema map[string]any, text string) any {
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj := make(map[string]any)
+		props, _ := schema["properties"].(map[string]any)
+		for name, prop := range props {
+			if prop, ok := prop.(map[string]any); ok {
+				obj[name] = stubValue(prop, text)
+			}
+		}
+		return obj
+	case "array":
+		n, _ := schema["minItems"].(float64)
+		items, _ := schema["items"].(map[string]any)
+		arr := make([]any, int(n))
+		for i := range arr {
+			arr[i] = stubValue(items, text)
+		}
+		return arr
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	default:
+		return text
+	}
+}
+
+// Requests returns the requests received so far.
+func (s *Stub) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
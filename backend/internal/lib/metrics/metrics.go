@@ -0,0 +1,61 @@
+// Package metrics declares the Prometheus metrics of the service.
+// They are registered in the default registry and served on /metrics.
+package metrics
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Cache layers.
+const (
+	// LayerLocal is the in-process LRU.
+	LayerLocal = "local"
+	// LayerRemote is the shared cache behind it, Redis.
+	LayerRemote = "remote"
+)
+
+var (
+	// CacheHits counts cache reads that found an entry, by layer and key namespace.
+	CacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "Cache reads that found an entry.",
+	}, []string{"layer", "namespace"})
+
+	// CacheMisses counts cache reads that found nothing, by layer and key namespace.
+	CacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_misses_total",
+		Help: "Cache reads that found nothing.",
+	}, []string{"layer", "namespace"})
+
+	// CacheCoalescedWaits counts cache misses that waited for a fill already running
+	// instead of loading the value themselves, by key namespace.
+	CacheCoalescedWaits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_coalesced_waits_total",
+		Help: "Cache misses that waited for a concurrent fill of the same key.",
+	}, []string{"namespace"})
+
+	// CategorySuggestions counts statements passed to the category classifier by the result:
+	// the source of the suggestion, "none" when there was none and "error" when classification failed.
+	CategorySuggestions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "category_suggestions_total",
+		Help: "Statements passed to the category classifier by result.",
+	}, []string{"result"})
+)
+
+// CacheNamespace returns the namespace of a cache key, the part before the first colon.
+func CacheNamespace(key string) string {
+	namespace, _, _ := strings.Cut(key, ":")
+	return namespace
+}
+
+// CountCacheRead counts a read of the key in the layer as a hit or a miss.
+func CountCacheRead(layer, key string, hit bool) {
+	if hit {
+		CacheHits.WithLabelValues(layer, CacheNamespace(key)).Inc()
+	} else {
+		CacheMisses.WithLabelValues(layer, CacheNamespace(key)).Inc()
+	}
+}
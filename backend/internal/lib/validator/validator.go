@@ -15,3 +15,19 @@ func init() {
 func ValidateStatement(statement *models.Statement) error {
 	return validate.Struct(statement)
 }
+
+func ValidateCredentials(credentials *models.Credentials) error {
+	return validate.Struct(credentials)
+}
+
+func ValidateNewUser(user *models.NewUser) error {
+	return validate.Struct(user)
+}
+
+func ValidateStatusChange(change *models.StatusChange) error {
+	return validate.Struct(change)
+}
+
+func ValidateRecommendation(recommendation *models.Recommendation) error {
+	return validate.Struct(recommendation)
+}
@@ -0,0 +1,174 @@
+package models
+
+import "time"
+
+// Bucket is the granularity of a time series.
+type Bucket string
+
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// Valid reports whether b is one of the supported buckets.
+func (b Bucket) Valid() bool {
+	switch b {
+	case BucketDay, BucketWeek, BucketMonth:
+		return true
+	}
+	return false
+}
+
+// Truncate returns the start of the bucket containing t in CityLocation.
+// Weeks start on Monday, like date_trunc('week') in PostgreSQL.
+func (b Bucket) Truncate(t time.Time) time.Time {
+	t = t.In(CityLocation)
+	y, m, d := t.Date()
+	switch b {
+	case BucketWeek:
+		day := time.Date(y, m, d, 0, 0, 0, 0, CityLocation)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, CityLocation)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, CityLocation)
+	}
+}
+
+// Next returns the start of the bucket following the one starting at start.
+func (b Bucket) Next(start time.Time) time.Time {
+	switch b {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Label formats the bucket start for chart axes: "2023-12-09" for days and weeks, "2023-12" for months.
+func (b Bucket) Label(start time.Time) string {
+	if b == BucketMonth {
+		return start.Format("2006-01")
+	}
+	return start.Format(DateLayout)
+}
+
+// PeriodPoint is the number of statements created within one bucket.
+type PeriodPoint struct {
+	Start time.Time `json:"start"`
+	Label string    `json:"label"`
+	Count int       `json:"count"`
+}
+
+// PeriodSeries is an ordered, gap-free series of period points.
+type PeriodSeries struct {
+	Bucket Bucket        `json:"bucket"`
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Total  int           `json:"total"`
+	Points []PeriodPoint `json:"points"`
+}
+
+// CrossTabCell is the number of statements of one category in one district.
+type CrossTabCell struct {
+	District string
+	Category string
+	Count    int
+}
+
+// CrossTabValue is a count with its percentage shares: of the row (district),
+// of the column (category) and of the grand total.
+type CrossTabValue struct {
+	Count       int     `json:"count"`
+	RowShare    float64 `json:"row_share"`
+	ColumnShare float64 `json:"column_share"`
+	Share       float64 `json:"share"`
+}
+
+// CrossTabRow holds the counts of one district keyed by category.
+type CrossTabRow struct {
+	District string                   `json:"district"`
+	Cells    map[string]CrossTabValue `json:"cells"`
+	Total    int                      `json:"total"`
+	Share    float64                  `json:"share"`
+}
+
+// CrossTabTotal is the total of one category over all districts.
+type CrossTabTotal struct {
+	Category string  `json:"category"`
+	Total    int     `json:"total"`
+	Share    float64 `json:"share"`
+}
+
+// CrossTab is the district × category matrix of statement counts.
+// Districts and categories are sorted by name, categories missing in a district have zero cells.
+type CrossTab struct {
+	Districts    []string        `json:"districts"`
+	Categories   []string        `json:"categories"`
+	Rows         []CrossTabRow   `json:"rows"`
+	ColumnTotals []CrossTabTotal `json:"column_totals"`
+	Total        int             `json:"total"`
+}
+
+// ResolutionGroup is the dimension resolution analytics are broken down by.
+type ResolutionGroup string
+
+const (
+	GroupByDistrict ResolutionGroup = "district"
+	GroupByCategory ResolutionGroup = "category"
+	GroupBySource   ResolutionGroup = "source"
+)
+
+// Valid reports whether g is one of the supported dimensions.
+func (g ResolutionGroup) Valid() bool {
+	switch g {
+	case GroupByDistrict, GroupByCategory, GroupBySource:
+		return true
+	}
+	return false
+}
+
+// ResolutionQuery selects statements for resolution analytics.
+// Statements resolved within SLA after creation count as closed in time,
+// open statements older than SLA count as overdue.
+type ResolutionQuery struct {
+	Filter  StatementFilter
+	GroupBy ResolutionGroup
+	SLA     time.Duration
+}
+
+// AgeBucket is the number of open statements whose age falls into [From, To).
+// To is omitted for the last, unbounded bucket.
+type AgeBucket struct {
+	Label     string   `json:"label"`
+	FromHours float64  `json:"from_hours"`
+	ToHours   *float64 `json:"to_hours,omitempty"`
+	Count     int      `json:"count"`
+}
+
+// ResolutionStats describes how fast statements of one group are resolved and how old the open ones are.
+// Durations are in hours.
+type ResolutionStats struct {
+	Key         string      `json:"key,omitempty"`
+	Resolved    int         `json:"resolved"`
+	MedianHours float64     `json:"median_hours"`
+	P90Hours    float64     `json:"p90_hours"`
+	MeanHours   float64     `json:"mean_hours"`
+	WithinSLA   int         `json:"within_sla"`
+	SLAShare    float64     `json:"sla_share"`
+	Open        int         `json:"open"`
+	Overdue     int         `json:"overdue"`
+	Backlog     []AgeBucket `json:"backlog"`
+}
+
+// ResolutionReport is the overall resolution statistics and its breakdown by GroupBy.
+type ResolutionReport struct {
+	SLAHours float64           `json:"sla_hours"`
+	GroupBy  ResolutionGroup   `json:"group_by"`
+	Total    ResolutionStats   `json:"total"`
+	Groups   []ResolutionStats `json:"groups"`
+}
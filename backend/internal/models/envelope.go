@@ -0,0 +1,192 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// EnvelopeVersion is the schema version of envelopes and payloads produced by this service.
+// Additive changes (new optional fields, new event types) keep the version,
+// anything that breaks existing consumers must increment it.
+const EnvelopeVersion = 1
+
+// EventName is the type of a domain event published to Kafka.
+type EventName string
+
+const (
+	EventStatementSubmitted     EventName = "statement.submitted"
+	EventStatementCreated       EventName = "statement.created"
+	EventStatementUpdated       EventName = "statement.updated"
+	EventStatementModerated     EventName = "statement.moderated"
+	EventStatementStatusChanged EventName = "statement.status_changed"
+	EventStatementDeleted       EventName = "statement.deleted"
+)
+
+// ErrUnsupportedEnvelope is returned for envelopes of unknown types or newer schema versions.
+var ErrUnsupportedEnvelope = errors.New("unsupported event envelope")
+
+// Actor is the user who caused the event.
+type Actor struct {
+	ID int64 `json:"id"`
+}
+
+// Envelope wraps every message published to Kafka.
+// Consumers must dispatch on Type, check Version and ignore unknown fields.
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       EventName       `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      *Actor          `json:"actor,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// StatementCreated is the payload of statement.created.
+type StatementCreated struct {
+	Statement Statement `json:"statement"`
+}
+
+// StatementUpdated is the payload of statement.updated.
+type StatementUpdated struct {
+	StatementID int                    `json:"statement_id"`
+	Changes     map[string]FieldChange `json:"changes"`
+	Statement   Statement              `json:"statement"`
+}
+
+// StatementModerated is the payload of statement.moderated.
+// Approved is false when the statement was sent back to the moderation queue.
+type StatementModerated struct {
+	StatementID int  `json:"statement_id"`
+	Approved    bool `json:"approved"`
+}
+
+// StatementStatusChanged is the payload of statement.status_changed.
+type StatementStatusChanged struct {
+	StatementID int    `json:"statement_id"`
+	From        Status `json:"from"`
+	To          Status `json:"to"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// StatementDeleted is the payload of statement.deleted with the last state of the statement.
+type StatementDeleted struct {
+	StatementID int       `json:"statement_id"`
+	Statement   Statement `json:"statement"`
+}
+
+// eventNames maps audit event types to envelope types.
+var eventNames = map[EventType]EventName{
+	EventCreated:       EventStatementCreated,
+	EventUpdated:       EventStatementUpdated,
+	EventModerated:     EventStatementModerated,
+	EventStatusChanged: EventStatementStatusChanged,
+	EventDeleted:       EventStatementDeleted,
+}
+
+// NewEnvelope wraps an audit event into an envelope.
+// statement is the state after the change, or the last state for deletions.
+func NewEnvelope(event StatementEvent, statement Statement) (Envelope, error) {
+	name, ok := eventNames[event.Type]
+	if !ok {
+		return Envelope{}, fmt.Errorf("event type %q: %w", event.Type, ErrUnsupportedEnvelope)
+	}
+
+	var payload any
+	switch event.Type {
+	case EventCreated:
+		payload = StatementCreated{Statement: statement}
+	case EventUpdated:
+		payload = StatementUpdated{StatementID: event.StatementID, Changes: event.Changes, Statement: statement}
+	case EventModerated:
+		payload = StatementModerated{StatementID: event.StatementID, Approved: !statement.AdminStatus}
+	case EventStatusChanged:
+		change := event.Changes["status"]
+		payload = StatementStatusChanged{
+			StatementID: event.StatementID,
+			From:        statusOf(change.Old),
+			To:          statusOf(change.New),
+			Reason:      event.Comment,
+		}
+	case EventDeleted:
+		payload = StatementDeleted{StatementID: event.StatementID, Statement: statement}
+	}
+
+	var actor *Actor
+	if event.ActorID != 0 {
+		actor = &Actor{ID: event.ActorID}
+	}
+
+	return WrapEvent(strconv.FormatInt(event.ID, 10), name, event.CreatedAt, actor, payload)
+}
+
+// WrapEvent builds an envelope of the current version around the payload.
+func WrapEvent(id string, name EventName, occurredAt time.Time, actor *Actor, payload any) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("marshal %s payload: %w", name, err)
+	}
+
+	return Envelope{
+		ID:         id,
+		Type:       name,
+		Version:    EnvelopeVersion,
+		OccurredAt: occurredAt,
+		Actor:      actor,
+		Payload:    data,
+	}, nil
+}
+
+// DecodeEnvelope parses an envelope and rejects versions newer than EnvelopeVersion.
+func DecodeEnvelope(data []byte) (Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Envelope{}, fmt.Errorf("unmarshal envelope: %w", err)
+	}
+	if env.Type == "" || env.Version < 1 {
+		return Envelope{}, fmt.Errorf("envelope without type or version: %w", ErrUnsupportedEnvelope)
+	}
+	if env.Version > EnvelopeVersion {
+		return Envelope{}, fmt.Errorf("%s version %d: %w", env.Type, env.Version, ErrUnsupportedEnvelope)
+	}
+	return env, nil
+}
+
+// DecodePayload returns the typed payload of the envelope, e.g. *StatementCreated for statement.created.
+func (e Envelope) DecodePayload() (any, error) {
+	var payload any
+	switch e.Type {
+	case EventStatementSubmitted:
+		payload = &Submission{}
+	case EventStatementCreated:
+		payload = &StatementCreated{}
+	case EventStatementUpdated:
+		payload = &StatementUpdated{}
+	case EventStatementModerated:
+		payload = &StatementModerated{}
+	case EventStatementStatusChanged:
+		payload = &StatementStatusChanged{}
+	case EventStatementDeleted:
+		payload = &StatementDeleted{}
+	default:
+		return nil, fmt.Errorf("event type %q: %w", e.Type, ErrUnsupportedEnvelope)
+	}
+
+	if err := json.Unmarshal(e.Payload, payload); err != nil {
+		return nil, fmt.Errorf("unmarshal %s payload: %w", e.Type, err)
+	}
+	return payload, nil
+}
+
+// statusOf converts a status stored in FieldChange, either as Status or as a decoded JSON string.
+func statusOf(v any) Status {
+	switch s := v.(type) {
+	case Status:
+		return s
+	case string:
+		return Status(s)
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// The files in testdata/events are messages in the shape consumers already rely on.
+// Never edit a fixture to make a test pass: a failure means the change breaks existing
+// consumers and needs a new EnvelopeVersion and a new set of fixtures.
+
+// TestEnvelope_Compatibility checks that every published version can still be decoded
+// and that the current types keep all of its fields.
+func TestEnvelope_Compatibility(t *testing.T) {
+	tests := []struct {
+		file string
+		want any
+	}{
+		{"statement.submitted.v1.json", &Submission{}},
+		{"statement.created.v1.json", &StatementCreated{}},
+		{"statement.updated.v1.json", &StatementUpdated{}},
+		{"statement.moderated.v1.json", &StatementModerated{}},
+		{"statement.status_changed.v1.json", &StatementStatusChanged{}},
+		{"statement.deleted.v1.json", &StatementDeleted{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "events", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			env, err := DecodeEnvelope(data)
+			if err != nil {
+				t.Fatalf("DecodeEnvelope() error = %v", err)
+			}
+			if env.ID == "" || env.OccurredAt.IsZero() {
+				t.Errorf("envelope without id or occurred_at: %+v", env)
+			}
+
+			payload, err := env.DecodePayload()
+			if err != nil {
+				t.Fatalf("DecodePayload() error = %v", err)
+			}
+			if reflect.TypeOf(payload) != reflect.TypeOf(tt.want) {
+				t.Fatalf("payload type = %T, want %T", payload, tt.want)
+			}
+
+			again, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := jsonValue(t, again), jsonValue(t, env.Payload); !reflect.DeepEqual(got, want) {
+				t.Errorf("payload does not survive a round trip\n got: %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNewEnvelope(t *testing.T) {
+	occurredAt := time.Date(2023, 12, 9, 10, 15, 0, 0, CityLocation)
+	statement := Statement{
+		StatementUID: 42,
+		Source:       "Городской портал",
+		District:     "Выборгский",
+		Category:     "Мусор",
+		Subcategory:  "Переполненные контейнеры",
+		CreatedAt:    occurredAt,
+		UpdatedAt:    occurredAt,
+		Status:       StatusRejected,
+		Description:  "Обращение по теме: переполненные контейнеры",
+	}
+
+	tests := []struct {
+		name  string
+		event StatementEvent
+		want  EventName
+		check func(t *testing.T, payload any)
+	}{
+		{
+			name:  "created",
+			event: StatementEvent{Type: EventCreated, Changes: DiffStatements(nil, &statement)},
+			want:  EventStatementCreated,
+			check: func(t *testing.T, payload any) {
+				if got := payload.(*StatementCreated).Statement.StatementUID; got != 42 {
+					t.Errorf("statement id = %d, want 42", got)
+				}
+			},
+		},
+		{
+			name:  "moderated",
+			event: StatementEvent{Type: EventModerated, Changes: map[string]FieldChange{"admin_status": {Old: true, New: false}}},
+			want:  EventStatementModerated,
+			check: func(t *testing.T, payload any) {
+				if !payload.(*StatementModerated).Approved {
+					t.Error("approved = false, want true")
+				}
+			},
+		},
+		{
+			name: "status changed",
+			event: StatementEvent{
+				Type:    EventStatusChanged,
+				Changes: map[string]FieldChange{"status": {Old: StatusNew, New: StatusRejected}},
+				Comment: "Дубликат",
+			},
+			want: EventStatementStatusChanged,
+			check: func(t *testing.T, payload any) {
+				want := &StatementStatusChanged{StatementID: 42, From: StatusNew, To: StatusRejected, Reason: "Дубликат"}
+				if !reflect.DeepEqual(payload, want) {
+					t.Errorf("payload = %+v, want %+v", payload, want)
+				}
+			},
+		},
+		{
+			name:  "deleted",
+			event: StatementEvent{Type: EventDeleted, Changes: DiffStatements(&statement, nil)},
+			want:  EventStatementDeleted,
+			check: func(t *testing.T, payload any) {
+				if got := payload.(*StatementDeleted).Statement.Description; got != statement.Description {
+					t.Errorf("description = %q, want %q", got, statement.Description)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.ID = 101
+			tt.event.StatementID = 42
+			tt.event.ActorID = 7
+			tt.event.CreatedAt = occurredAt
+
+			env, err := NewEnvelope(tt.event, statement)
+			if err != nil {
+				t.Fatalf("NewEnvelope() error = %v", err)
+			}
+			data, err := json.Marshal(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := DecodeEnvelope(data)
+			if err != nil {
+				t.Fatalf("DecodeEnvelope() error = %v", err)
+			}
+			if decoded.ID != "101" || decoded.Type != tt.want || decoded.Version != EnvelopeVersion {
+				t.Errorf("envelope = %s %s v%d, want 101 %s v%d", decoded.ID, decoded.Type, decoded.Version, tt.want, EnvelopeVersion)
+			}
+			if decoded.Actor == nil || decoded.Actor.ID != 7 {
+				t.Errorf("actor = %+v, want 7", decoded.Actor)
+			}
+			if !decoded.OccurredAt.Equal(occurredAt) {
+				t.Errorf("occurred_at = %v, want %v", decoded.OccurredAt, occurredAt)
+			}
+
+			payload, err := decoded.DecodePayload()
+			if err != nil {
+				t.Fatalf("DecodePayload() error = %v", err)
+			}
+			tt.check(t, payload)
+		})
+	}
+}
+
+func TestDecodeEnvelope_Unsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"newer version", `{"id":"1","type":"statement.created","version":2,"payload":{}}`},
+		{"no type", `{"id":"1","version":1,"payload":{}}`},
+		{"no version", `{"id":"1","type":"statement.created","payload":{}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeEnvelope([]byte(tt.data)); !errors.Is(err, ErrUnsupportedEnvelope) {
+				t.Errorf("DecodeEnvelope() error = %v, want ErrUnsupportedEnvelope", err)
+			}
+		})
+	}
+
+	env, err := DecodeEnvelope([]byte(`{"id":"1","type":"statement.archived","version":1,"payload":{},"extra":true}`))
+	if err != nil {
+		t.Fatalf("DecodeEnvelope() error = %v, unknown fields must be ignored", err)
+	}
+	if _, err := env.DecodePayload(); !errors.Is(err, ErrUnsupportedEnvelope) {
+		t.Errorf("DecodePayload() error = %v, want ErrUnsupportedEnvelope", err)
+	}
+}
+
+func jsonValue(t *testing.T, data []byte) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
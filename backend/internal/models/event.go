@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// EventType is the kind of change recorded in statement_events.
+type EventType string
+
+const (
+	EventCreated       EventType = "created"
+	EventUpdated       EventType = "updated"
+	EventModerated     EventType = "moderated"
+	EventStatusChanged EventType = "status_changed"
+	EventDeleted       EventType = "deleted"
+)
+
+// FieldChange is the old and new value of a single field.
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// StatementEvent is an audit record of a statement change.
+type StatementEvent struct {
+	ID          int64                  `json:"id"`
+	StatementID int                    `json:"statement_id"`
+	Type        EventType              `json:"type"`
+	ActorID     int64                  `json:"actor_id,omitempty"`
+	Changes     map[string]FieldChange `json:"changes"`
+	Comment     string                 `json:"comment,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// auditedFields lists statement fields tracked by DiffStatements, keyed by their JSON names.
+var auditedFields = []struct {
+	name  string
+	value func(s *Statement) any
+}{
+	{"source", func(s *Statement) any { return s.Source }},
+	{"district", func(s *Statement) any { return s.District }},
+	{"category", func(s *Statement) any { return s.Category }},
+	{"subcategory", func(s *Statement) any { return s.Subcategory }},
+	{"status", func(s *Statement) any { return s.Status }},
+	{"admin_status", func(s *Statement) any { return s.AdminStatus }},
+	{"description", func(s *Statement) any { return s.Description }},
+}
+
+// DiffStatements returns the changed fields between two versions of a statement.
+// A nil old describes a creation, a nil updated describes a deletion.
+func DiffStatements(old, updated *Statement) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	for _, f := range auditedFields {
+		var o, n any
+		if old != nil {
+			o = f.value(old)
+		}
+		if updated != nil {
+			n = f.value(updated)
+		}
+		if o != n {
+			changes[f.name] = FieldChange{Old: o, New: n}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffStatements(t *testing.T) {
+	old := Statement{
+		StatementUID: 1,
+		Source:       "Городской портал",
+		District:     "Выборгский",
+		Category:     "Мусор",
+		Subcategory:  "Переполненные контейнеры",
+		Status:       StatusNew,
+		AdminStatus:  true,
+		Description:  "Обращение по теме: переполненные контейнеры",
+	}
+
+	approved := old
+	approved.AdminStatus = false
+
+	moved := old
+	moved.District = "Петроградский"
+	moved.Status = StatusInWork
+
+	tests := []struct {
+		name    string
+		old     *Statement
+		updated *Statement
+		want    map[string]FieldChange
+	}{
+		{name: "unchanged", old: &old, updated: &old, want: map[string]FieldChange{}},
+		{
+			name: "moderation", old: &old, updated: &approved,
+			want: map[string]FieldChange{"admin_status": {Old: true, New: false}},
+		},
+		{
+			name: "two fields", old: &old, updated: &moved,
+			want: map[string]FieldChange{
+				"district": {Old: "Выборгский", New: "Петроградский"},
+				"status":   {Old: StatusNew, New: StatusInWork},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffStatements(tt.old, tt.updated); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffStatements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("created", func(t *testing.T) {
+		got := DiffStatements(nil, &old)
+		if len(got) != len(auditedFields) {
+			t.Fatalf("DiffStatements(nil, s) has %d fields, want %d", len(got), len(auditedFields))
+		}
+		if got["district"] != (FieldChange{Old: nil, New: "Выборгский"}) {
+			t.Errorf("district = %v", got["district"])
+		}
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		got := DiffStatements(&old, nil)
+		if got["status"] != (FieldChange{Old: StatusNew, New: nil}) {
+			t.Errorf("status = %v", got["status"])
+		}
+	})
+}
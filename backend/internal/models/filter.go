@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// StatementFilter narrows statements down. Empty slices and zero times mean "any".
+// Multiple values of one field are combined with OR, different fields with AND.
+type StatementFilter struct {
+	Districts     []string
+	Categories    []string
+	Subcategories []string
+	Statuses      []Status
+	Sources       []string
+	AdminStatus   *bool
+	// From is inclusive, To is exclusive.
+	From time.Time
+	To   time.Time
+}
+
+// SortField is a column statements can be ordered by.
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+)
+
+// Valid reports whether f is one of the supported sort fields.
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByID, SortByCreatedAt, SortByUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// PageCursor is the position after the last returned row: its sort value and id.
+// The sort value is empty when sorting by id.
+type PageCursor struct {
+	SortBy    SortField `json:"s"`
+	Desc      bool      `json:"d"`
+	SortValue time.Time `json:"v"`
+	ID        int       `json:"i"`
+}
+
+// StatementQuery describes one page of a statement list.
+type StatementQuery struct {
+	Filter StatementFilter
+	SortBy SortField
+	Desc   bool
+	Limit  int
+	After  *PageCursor
+}
+
+// StatementPage is one page of statements with the total number of matches.
+type StatementPage struct {
+	Items      []Statement `json:"items"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyKey is a processed request remembered to return its original result on a replay.
+// RequestHash tells a replay from a different request sent with the same key.
+// CompletedAt is nil while the request has not finished, e.g. its publication failed.
+// After ExpiresAt the key is forgotten and purged.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+	Result      json.RawMessage
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   time.Time
+}
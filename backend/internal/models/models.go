@@ -1,13 +1,64 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the date-only format used by imported datasets, e.g. "2023-12-09".
+const DateLayout = "2006-01-02"
+
+// CityLocation is the St. Petersburg time zone (UTC+3, no daylight saving time).
+// Dates without a time zone are interpreted in it.
+var CityLocation = time.FixedZone("MSK", 3*60*60)
+
 type Statement struct {
-	StatementUID int    `json:"id"`
-	Source       string `json:"source" validate:"required"`
-	District     string `json:"district" validate:"required"`
-	Category     string `json:"category" validate:"required"`
-	Subcategory  string `json:"subcategory" validate:"required"`
-	CreatedAt    string `json:"created_at"`
-	Status       string `json:"status" validate:"required"`
-	AdminStatus  bool `json:"admin_status"`
-	Description  string `json:"description" validate:"required,min=10"`
+	StatementUID int        `json:"id"`
+	Source       string     `json:"source" validate:"required"`
+	District     string     `json:"district" validate:"required"`
+	Category     string     `json:"category" validate:"required"`
+	Subcategory  string     `json:"subcategory" validate:"required"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	Status       Status     `json:"status" validate:"required"`
+	AdminStatus  bool       `json:"admin_status"`
+	Description  string     `json:"description" validate:"required,min=10"`
+	// Suggestion is set by the classifier for statements awaiting moderation.
+	Suggestion *CategorySuggestion `json:"suggestion,omitempty"`
+}
+
+// UnmarshalJSON accepts created_at both in RFC 3339 and as a plain date,
+// so datasets like "Город будущего.json" can be imported as is.
+func (s *Statement) UnmarshalJSON(data []byte) error {
+	type statement Statement
+	aux := struct {
+		*statement
+		CreatedAt string `json:"created_at"`
+	}{statement: (*statement)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	createdAt, err := ParseTime(aux.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("created_at: %w", err)
+	}
+	s.CreatedAt = createdAt
+
+	return nil
+}
+
+// ParseTime parses an RFC 3339 timestamp or a date in DateLayout.
+// An empty string yields the zero time.
+func ParseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(DateLayout, value, CityLocation)
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStatement_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "date", data: `{"created_at": "2023-12-09"}`, want: time.Date(2023, 12, 9, 0, 0, 0, 0, CityLocation)},
+		{name: "rfc3339", data: `{"created_at": "2023-12-09T10:30:00Z"}`, want: time.Date(2023, 12, 9, 10, 30, 0, 0, time.UTC)},
+		{name: "empty", data: `{}`, want: time.Time{}},
+		{name: "invalid", data: `{"created_at": "09.12.2023"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Statement
+			err := json.Unmarshal([]byte(tt.data), &s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !s.CreatedAt.Equal(tt.want) {
+				t.Errorf("CreatedAt = %v, want %v", s.CreatedAt, tt.want)
+			}
+		})
+	}
+
+	var s Statement
+	if err := json.Unmarshal([]byte(`{"id": 7, "district": "Невский", "status": "Новое"}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.StatementUID != 7 || s.District != "Невский" || s.Status != StatusNew {
+		t.Errorf("other fields not decoded: %+v", s)
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Outbox topics. Every topic is published to its own Kafka topic by its own relay.
+const (
+	// OutboxEvents are statement events, written in the transaction of the change they describe.
+	OutboxEvents = "events"
+	// OutboxSubmissions are submissions CreateStatement could not publish to Kafka directly.
+	OutboxSubmissions = "submissions"
+)
+
+// OutboxMessage is a message waiting in the outbox to be published to Kafka.
+type OutboxMessage struct {
+	ID        int64
+	Topic     string
+	Key       string
+	Payload   []byte
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Priority is the urgency of a recommendation.
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
+// Valid reports whether p is one of the known priorities.
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityHigh, PriorityMedium, PriorityLow:
+		return true
+	}
+	return false
+}
+
+// Recommendation is an advice for citizens generated from recent statements.
+type Recommendation struct {
+	Title      string   `json:"title" validate:"required,max=200"`
+	Body       string   `json:"body" validate:"required,max=2000"`
+	Districts  []string `json:"districts" validate:"dive,required"`
+	Categories []string `json:"categories" validate:"dive,required"`
+	Priority   Priority `json:"priority" validate:"required,oneof=high medium low"`
+}
+
+// RecommendationQuery selects the statements recommendations are grounded in.
+// Only approved statements are used whatever Filter.AdminStatus is.
+// Resolution statistics are added to the prompt when SLA is positive.
+type RecommendationQuery struct {
+	Filter StatementFilter
+	Count  int
+	SLA    time.Duration
+}
@@ -0,0 +1,22 @@
+package models
+
+// SearchQuery is a full-text search over statements combined with the usual filter.
+type SearchQuery struct {
+	Text   string
+	Filter StatementFilter
+	Limit  int
+	Offset int
+}
+
+// SearchHit is a matching statement with its rank and a highlighted description fragment.
+type SearchHit struct {
+	Statement Statement `json:"statement"`
+	Rank      float64   `json:"rank"`
+	Snippet   string    `json:"snippet"`
+}
+
+// SearchResult is one page of search hits ordered by rank.
+type SearchResult struct {
+	Items []SearchHit `json:"items"`
+	Total int         `json:"total"`
+}
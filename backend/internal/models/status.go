@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Status is the processing status of a statement.
+type Status string
+
+const (
+	StatusNew      Status = "Новое"
+	StatusInWork   Status = "В работе"
+	StatusResolved Status = "Решено"
+	StatusRejected Status = "Отклонено"
+	StatusReopened Status = "Переоткрыто"
+)
+
+// Valid reports whether s is one of the known statuses.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusNew, StatusInWork, StatusResolved, StatusRejected, StatusReopened:
+		return true
+	}
+	return false
+}
+
+// StatusTransition is a recorded change of a statement status.
+type StatusTransition struct {
+	ID          int64     `json:"id"`
+	StatementID int       `json:"statement_id"`
+	From        Status    `json:"from"`
+	To          Status    `json:"to"`
+	ActorID     int64     `json:"actor_id"`
+	Reason      string    `json:"reason,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StatusChange is the request body of POST /api/statement/{id}/status.
+type StatusChange struct {
+	Status Status `json:"status" validate:"required"`
+	Reason string `json:"reason" validate:"max=1000"`
+}
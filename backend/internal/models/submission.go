@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Submission is a batch of statements accepted by the HTTP API.
+// It is published to Kafka and stored by the ingestion worker.
+type Submission struct {
+	TrackingID  string      `json:"tracking_id"`
+	ActorID     int64       `json:"actor_id"`
+	SubmittedAt time.Time   `json:"submitted_at"`
+	Statements  []Statement `json:"statements"`
+}
+
+// SubmissionResult is the outcome of a submission remembered under its idempotency key:
+// the tracking ID returned by the HTTP API or the IDs of the statements stored by the worker.
+type SubmissionResult struct {
+	TrackingID   string `json:"tracking_id,omitempty"`
+	StatementIDs []int  `json:"statement_ids,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Sources of category suggestions.
+const (
+	// SuggestionLLM is a suggestion of the LLM provider.
+	SuggestionLLM = "llm"
+	// SuggestionLocal is a suggestion of the TF-IDF model trained on approved statements.
+	SuggestionLocal = "local"
+)
+
+// CategorySuggestion is the category and subcategory the classifier suggests for a statement
+// instead of the ones chosen by the citizen. Confidence is from 0 to 1.
+type CategorySuggestion struct {
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory"`
+	Confidence  float64 `json:"confidence"`
+	Source      string  `json:"source"`
+}
+
+// SuggestionSettled reports whether the update of a statement from old to updated settles its suggestion:
+// the category or subcategory is chosen by hand, or they are already the suggested ones, e.g. the suggestion is accepted.
+// A settled suggestion is dropped, so it is not offered again.
+func SuggestionSettled(old, updated *Statement) bool {
+	if old.Suggestion == nil {
+		return false
+	}
+	if old.Category != updated.Category || old.Subcategory != updated.Subcategory {
+		return true
+	}
+	return old.Suggestion.Category == updated.Category && old.Suggestion.Subcategory == updated.Subcategory
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Role is a user role stored in users.role.
+type Role string
+
+const (
+	RoleCitizen   Role = "citizen"
+	RoleModerator Role = "moderator"
+	RoleExecutor  Role = "executor"
+	RoleAdmin     Role = "admin"
+)
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleCitizen, RoleModerator, RoleExecutor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+type User struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	Role         Role      `json:"role"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Session struct {
+	TokenHash string
+	UserID    int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type Credentials struct {
+	Username string `json:"username" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,min=6,max=72"`
+}
+
+// NewUser is the admin API request for creating an account.
+type NewUser struct {
+	Credentials
+	Role Role `json:"role" validate:"required"`
+}
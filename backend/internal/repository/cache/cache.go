@@ -0,0 +1,125 @@
+// Package cache provides cache implementations used when Redis is unavailable.
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache is a tagged key-value cache, implemented by redis.Redis.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte, ttl time.Duration, tags ...string) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// NoOp is a cache that keeps nothing: every read is a miss.
+type NoOp struct{}
+
+// Get always misses.
+func (NoOp) Get(context.Context, string) ([]byte, error) { return nil, nil }
+
+// Set drops the data.
+func (NoOp) Set(context.Context, string, []byte, time.Duration, ...string) error { return nil }
+
+// Delete does nothing.
+func (NoOp) Delete(context.Context, ...string) error { return nil }
+
+// InvalidateTags does nothing.
+func (NoOp) InvalidateTags(context.Context, ...string) error { return nil }
+
+// Fallback uses the cache while available reports true and NoOp otherwise,
+// so requests do not wait for timeouts of an unreachable cache.
+// Deletions and invalidations missed while the cache is unavailable are replayed once it is back,
+// otherwise it would serve data changed during the outage.
+type Fallback struct {
+	cache     Cache
+	available func() bool
+
+	mu         sync.Mutex
+	missedKeys map[string]struct{}
+	missedTags map[string]struct{}
+}
+
+// NewFallback creates a cache falling back to NoOp while available reports false.
+func NewFallback(cache Cache, available func() bool) *Fallback {
+	return &Fallback{
+		cache:      cache,
+		available:  available,
+		missedKeys: make(map[string]struct{}),
+		missedTags: make(map[string]struct{}),
+	}
+}
+
+// current returns the cache after replaying missed deletions, or NoOp if it is unavailable.
+func (f *Fallback) current(ctx context.Context) Cache {
+	if !f.available() {
+		return NoOp{}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key := range f.missedKeys {
+		if err := f.cache.Delete(ctx, key); err != nil {
+			return NoOp{}
+		}
+		delete(f.missedKeys, key)
+	}
+	for tag := range f.missedTags {
+		if err := f.cache.InvalidateTags(ctx, tag); err != nil {
+			return NoOp{}
+		}
+		delete(f.missedTags, tag)
+	}
+	return f.cache
+}
+
+// Get reads the cache, or misses while it is unavailable.
+func (f *Fallback) Get(ctx context.Context, key string) ([]byte, error) {
+	return f.current(ctx).Get(ctx, key)
+}
+
+// Set writes the cache, or drops the data while it is unavailable.
+func (f *Fallback) Set(ctx context.Context, key string, data []byte, ttl time.Duration, tags ...string) error {
+	return f.current(ctx).Set(ctx, key, data, ttl, tags...)
+}
+
+// Delete deletes from the cache, or remembers the deletion while it is unavailable.
+func (f *Fallback) Delete(ctx context.Context, keys ...string) error {
+	cache := f.current(ctx)
+	if _, ok := cache.(NoOp); ok {
+		f.miss(f.missedKeys, keys)
+		return nil
+	}
+	if err := cache.Delete(ctx, keys...); err != nil {
+		f.miss(f.missedKeys, keys)
+		return err
+	}
+	return nil
+}
+
+// InvalidateTags invalidates the tags, or remembers the invalidation while the cache is unavailable.
+func (f *Fallback) InvalidateTags(ctx context.Context, tags ...string) error {
+	cache := f.current(ctx)
+	if _, ok := cache.(NoOp); ok {
+		f.miss(f.missedTags, tags)
+		return nil
+	}
+	if err := cache.InvalidateTags(ctx, tags...); err != nil {
+		f.miss(f.missedTags, tags)
+		return err
+	}
+	return nil
+}
+
+func (f *Fallback) miss(missed map[string]struct{}, values []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, v := range values {
+		missed[v] = struct{}{}
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mapCache keeps entries in a map, tags are ignored and invalidate everything.
+type mapCache map[string][]byte
+
+func (m mapCache) Get(_ context.Context, key string) ([]byte, error) { return m[key], nil }
+
+func (m mapCache) Set(_ context.Context, key string, data []byte, _ time.Duration, _ ...string) error {
+	m[key] = data
+	return nil
+}
+
+func (m mapCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(m, key)
+	}
+	return nil
+}
+
+func (m mapCache) InvalidateTags(_ context.Context, _ ...string) error {
+	clear(m)
+	return nil
+}
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+	redis := mapCache{}
+	up := true
+	c := NewFallback(redis, func() bool { return up })
+
+	c.Set(ctx, "stmt:1", []byte("v1"), time.Hour)
+	c.Set(ctx, "list:a", []byte("page"), time.Hour, "statements")
+
+	up = false
+	if data, _ := c.Get(ctx, "stmt:1"); data != nil {
+		t.Errorf("Get() = %q while unavailable, want a miss", data)
+	}
+	if err := c.Delete(ctx, "stmt:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InvalidateTags(ctx, "statements"); err != nil {
+		t.Fatal(err)
+	}
+	if len(redis) != 2 {
+		t.Fatal("cache was called while unavailable")
+	}
+
+	up = true
+	if data, _ := c.Get(ctx, "list:a"); data != nil {
+		t.Errorf("Get() = %q after the outage, want the missed invalidation replayed", data)
+	}
+	if len(c.missedKeys) != 0 || len(c.missedTags) != 0 {
+		t.Errorf("missed %v, %v after replay", c.missedKeys, c.missedTags)
+	}
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"hack/internal/lib/metrics"
+)
+
+// Local is a bounded in-process LRU in front of a shared cache. Entries live there for at most ttl,
+// so changes made by other instances are seen after ttl at the latest.
+// Writes and invalidations go to both caches.
+type Local struct {
+	next Cache
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // of *localEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type localEntry struct {
+	key  string
+	data []byte
+	// tags are nil for entries read from the shared cache, which does not return them.
+	tags      []string
+	expiresAt time.Time
+}
+
+// NewLocal creates an LRU of up to size entries kept for at most ttl in front of next.
+func NewLocal(next Cache, size int, ttl time.Duration) *Local {
+	return &Local{
+		next:    next,
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the local entry, or reads the shared cache and keeps the entry locally.
+// The returned data must not be modified.
+func (l *Local) Get(ctx context.Context, key string) ([]byte, error) {
+	if data, ok := l.get(key); ok {
+		metrics.CountCacheRead(metrics.LayerLocal, key, true)
+		return data, nil
+	}
+	metrics.CountCacheRead(metrics.LayerLocal, key, false)
+
+	data, err := l.next.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	metrics.CountCacheRead(metrics.LayerRemote, key, data != nil)
+	if data != nil {
+		l.put(key, data, l.ttl, nil)
+	}
+
+	return data, nil
+}
+
+// Set writes the entry to the shared cache and keeps it locally.
+func (l *Local) Set(ctx context.Context, key string, data []byte, ttl time.Duration, tags ...string) error {
+	if err := l.next.Set(ctx, key, data, ttl, tags...); err != nil {
+		return err
+	}
+
+	local := l.ttl
+	if ttl > 0 && ttl < local {
+		local = ttl
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	l.put(key, append([]byte(nil), data...), local, tags)
+
+	return nil
+}
+
+// Delete removes the keys from both caches.
+func (l *Local) Delete(ctx context.Context, keys ...string) error {
+	l.mu.Lock()
+	for _, key := range keys {
+		l.remove(key)
+	}
+	l.mu.Unlock()
+
+	return l.next.Delete(ctx, keys...)
+}
+
+// InvalidateTags removes the entries with any of the tags from both caches.
+// Local entries read from the shared cache have unknown tags and are removed as well.
+func (l *Local) InvalidateTags(ctx context.Context, tags ...string) error {
+	l.mu.Lock()
+	for key, elem := range l.entries {
+		entry := elem.Value.(*localEntry)
+		if entry.tags == nil || slices.ContainsFunc(entry.tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			l.remove(key)
+		}
+	}
+	l.mu.Unlock()
+
+	return l.next.InvalidateTags(ctx, tags...)
+}
+
+func (l *Local) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*localEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		l.remove(key)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+
+	return entry.data, true
+}
+
+// put keeps the entry, evicting the least recently used one when the cache is full.
+func (l *Local) put(key string, data []byte, ttl time.Duration, tags []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := &localEntry{key: key, data: data, tags: tags, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := l.entries[key]; ok {
+		elem.Value = entry
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(entry)
+	if l.order.Len() > l.size {
+		l.remove(l.order.Back().Value.(*localEntry).key)
+	}
+}
+
+// remove drops the entry. The caller holds the lock.
+func (l *Local) remove(key string) {
+	if elem, ok := l.entries[key]; ok {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLocal_Evicts(t *testing.T) {
+	ctx := context.Background()
+	remote := mapCache{}
+	l := NewLocal(remote, 2, time.Minute)
+
+	l.Set(ctx, "a", []byte("1"), time.Hour)
+	l.Set(ctx, "b", []byte("2"), time.Hour)
+	l.Get(ctx, "a")
+	l.Set(ctx, "c", []byte("3"), time.Hour)
+
+	if _, ok := l.get("b"); ok {
+		t.Error("b is kept, want the least recently used entry evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.get(key); !ok {
+			t.Errorf("%s is evicted", key)
+		}
+	}
+
+	delete(remote, "a")
+	if data, _ := l.Get(ctx, "a"); string(data) != "1" {
+		t.Errorf("Get(a) = %q, want the local entry", data)
+	}
+}
+
+func TestLocal_TTL(t *testing.T) {
+	ctx := context.Background()
+	remote := mapCache{}
+	l := NewLocal(remote, 10, time.Millisecond)
+
+	l.Set(ctx, "a", []byte("1"), time.Hour)
+	remote["a"] = []byte("changed by another instance")
+	time.Sleep(5 * time.Millisecond)
+
+	if data, _ := l.Get(ctx, "a"); string(data) != "changed by another instance" {
+		t.Errorf("Get(a) = %q after the local ttl, want the remote entry", data)
+	}
+}
+
+func TestLocal_InvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	remote := mapCache{"remote": []byte("r")}
+	l := NewLocal(remote, 10, time.Minute)
+
+	l.Set(ctx, "tagged", []byte("1"), time.Hour, "statements")
+	l.Set(ctx, "other", []byte("2"), time.Hour, "recs")
+	l.Set(ctx, "untagged", []byte("3"), time.Hour)
+	l.Get(ctx, "remote")
+
+	if err := l.InvalidateTags(ctx, "statements"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{"tagged": false, "other": true, "untagged": true, "remote": false}
+	for key, kept := range want {
+		if _, ok := l.get(key); ok != kept {
+			t.Errorf("%s kept = %v, want %v", key, ok, kept)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+// Package repository contains errors shared by all storage implementations.
+// Use cases match on them with errors.Is without depending on a concrete storage.
+package repository
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when a unique constraint would be violated.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict is returned when the record was changed concurrently.
+	ErrConflict = errors.New("concurrent modification")
+)
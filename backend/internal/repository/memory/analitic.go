@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"hack/internal/models"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// GetPeriodAnalitic counts statements matching the filter by day, week or month.
+// Only non-empty buckets are returned, in chronological order.
+func (s *Storage) GetPeriodAnalitic(_ context.Context, filter models.StatementFilter, bucket models.Bucket) ([]models.PeriodPoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[time.Time]int)
+	for _, stmt := range s.sortedStatements(filter) {
+		counts[bucket.Truncate(stmt.CreatedAt)]++
+	}
+
+	points := make([]models.PeriodPoint, 0, len(counts))
+	for start, count := range counts {
+		points = append(points, models.PeriodPoint{Start: start, Count: count})
+	}
+	slices.SortFunc(points, func(a, b models.PeriodPoint) int { return a.Start.Compare(b.Start) })
+
+	return points, nil
+}
+
+// GetCategoriesAnalitic counts statements matching the filter by category.
+func (s *Storage) GetCategoriesAnalitic(_ context.Context, filter models.StatementFilter) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	analitic := make(map[string]int)
+	for _, stmt := range s.sortedStatements(filter) {
+		analitic[stmt.Category]++
+	}
+	return analitic, nil
+}
+
+// GetDistrictAnalitic counts approved statements by district.
+func (s *Storage) GetDistrictAnalitic(_ context.Context) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	approved := false
+	analitic := make(map[string]int)
+	for _, stmt := range s.sortedStatements(models.StatementFilter{AdminStatus: &approved}) {
+		analitic[stmt.District]++
+	}
+	return analitic, nil
+}
+
+// GetCrossTabAnalitic counts statements matching the filter by (district, category) pairs.
+func (s *Storage) GetCrossTabAnalitic(_ context.Context, filter models.StatementFilter) ([]models.CrossTabCell, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type pair struct{ district, category string }
+	counts := make(map[pair]int)
+	for _, stmt := range s.sortedStatements(filter) {
+		counts[pair{stmt.District, stmt.Category}]++
+	}
+
+	cells := make([]models.CrossTabCell, 0, len(counts))
+	for p, count := range counts {
+		cells = append(cells, models.CrossTabCell{District: p.district, Category: p.category, Count: count})
+	}
+	return cells, nil
+}
+
+// GetResolutionAnalitic computes resolution times and backlog ages of the statements matching the filter,
+// in total and broken down by q.GroupBy, the same way as postgres.Storage.
+// The resolution time is taken from resolved_at.
+func (s *Storage) GetResolutionAnalitic(_ context.Context, q models.ResolutionQuery, ageBounds []time.Duration) (models.ResolutionStats, []models.ResolutionStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := &models.ResolutionStats{Backlog: make([]models.AgeBucket, len(ageBounds)+1)}
+	groups := make(map[string]*models.ResolutionStats)
+	targets := func(stmt models.Statement) []*models.ResolutionStats {
+		if q.GroupBy == "" {
+			return []*models.ResolutionStats{total}
+		}
+		key := groupKey(q.GroupBy, stmt)
+		if groups[key] == nil {
+			groups[key] = &models.ResolutionStats{Key: key, Backlog: make([]models.AgeBucket, len(ageBounds)+1)}
+		}
+		return []*models.ResolutionStats{total, groups[key]}
+	}
+
+	seconds := make(map[*models.ResolutionStats][]float64)
+	now := time.Now()
+	for _, stmt := range s.sortedStatements(q.Filter) {
+		switch stmt.Status {
+		case models.StatusResolved:
+			if stmt.ResolvedAt == nil {
+				continue
+			}
+			for _, st := range targets(stmt) {
+				seconds[st] = append(seconds[st], stmt.ResolvedAt.Sub(stmt.CreatedAt).Seconds())
+			}
+		case models.StatusRejected:
+		default:
+			age := now.Sub(stmt.CreatedAt)
+			bucket := sort.Search(len(ageBounds), func(i int) bool { return age < ageBounds[i] })
+			for _, st := range targets(stmt) {
+				st.Open++
+				st.Backlog[bucket].Count++
+				if age > q.SLA {
+					st.Overdue++
+				}
+			}
+		}
+	}
+
+	for st, values := range seconds {
+		slices.Sort(values)
+		st.Resolved = len(values)
+		st.MedianHours = percentile(values, 0.5) / 3600
+		st.P90Hours = percentile(values, 0.9) / 3600
+		var sum float64
+		for _, v := range values {
+			sum += v
+			if v <= q.SLA.Seconds() {
+				st.WithinSLA++
+			}
+		}
+		st.MeanHours = sum / float64(len(values)) / 3600
+	}
+
+	result := make([]models.ResolutionStats, 0, len(groups))
+	for _, st := range groups {
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	return *total, result, nil
+}
+
+func groupKey(group models.ResolutionGroup, stmt models.Statement) string {
+	switch group {
+	case models.GroupByDistrict:
+		return stmt.District
+	case models.GroupByCategory:
+		return stmt.Category
+	case models.GroupBySource:
+		return stmt.Source
+	}
+	return ""
+}
+
+// percentile interpolates between the sorted values like percentile_cont.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower == len(sorted)-1 {
+		return sorted[lower]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
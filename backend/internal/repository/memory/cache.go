@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cacheSweepInterval is how often Set drops all expired entries, so entries that are never read again do not pile up.
+const cacheSweepInterval = time.Minute
+
+// Cache is an in-memory replacement of the Redis cache. Expired entries are dropped on read
+// and by a sweep every cacheSweepInterval. A removed entry leaves the sets of its tags.
+type Cache struct {
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	tags      map[string]map[string]struct{}
+	lastSweep time.Time
+}
+
+type cacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+	tags      []string
+}
+
+// expired reports whether the entry has expired by now.
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewCache creates an empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		entries:   make(map[string]cacheEntry),
+		tags:      make(map[string]map[string]struct{}),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the cached data, or nil when there is none.
+func (c *Cache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if entry.expired(time.Now()) {
+		c.remove(key)
+		return nil, nil
+	}
+	return append([]byte(nil), entry.data...), nil
+}
+
+// Set caches a copy of the data for ttl, zero ttl means forever like in Redis.
+func (c *Cache) Set(_ context.Context, key string, data []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= cacheSweepInterval {
+		c.sweep(now)
+	}
+
+	c.remove(key)
+	entry := cacheEntry{data: append([]byte(nil), data...), tags: append([]string(nil), tags...)}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	c.entries[key] = entry
+
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	return nil
+}
+
+// Delete removes the cached data.
+func (c *Cache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		c.remove(key)
+	}
+
+	return nil
+}
+
+// InvalidateTags removes every entry set with any of the tags.
+func (c *Cache) InvalidateTags(_ context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(key)
+		}
+		delete(c.tags, tag)
+	}
+
+	return nil
+}
+
+// Close does nothing, it lets Cache replace the Redis client on shutdown.
+func (c *Cache) Close() error {
+	return nil
+}
+
+// remove deletes the entry and takes its key out of the tag sets, dropping the emptied ones.
+// The caller holds c.mu.
+func (c *Cache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, tag := range entry.tags {
+		keys := c.tags[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// sweep removes the entries expired by now. The caller holds c.mu.
+func (c *Cache) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			c.remove(key)
+		}
+	}
+	c.lastSweep = now
+}
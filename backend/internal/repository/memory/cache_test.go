@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCache_TagsPruned(t *testing.T) {
+	c := NewCache()
+	ctx := context.Background()
+
+	c.Set(ctx, "list:1", []byte("a"), time.Minute, "statements")
+	c.Set(ctx, "list:2", []byte("b"), time.Nanosecond, "statements")
+	c.Set(ctx, "stmt:1", []byte("c"), time.Minute, "statements", "stmt")
+	c.Delete(ctx, "stmt:1")
+	time.Sleep(time.Millisecond)
+
+	if data, _ := c.Get(ctx, "list:2"); data != nil {
+		t.Fatalf("expired entry = %q, want none", data)
+	}
+	if keys := c.tags["statements"]; len(keys) != 1 {
+		t.Errorf("statements tag = %v, want only list:1", keys)
+	}
+	if _, ok := c.tags["stmt"]; ok {
+		t.Error("tag of the deleted entry is kept")
+	}
+
+	c.lastSweep = time.Time{}
+	c.Set(ctx, "list:3", []byte("d"), time.Nanosecond, "statements")
+	time.Sleep(time.Millisecond)
+	c.lastSweep = time.Time{}
+	c.Set(ctx, "recs:1", []byte("e"), time.Minute)
+	if _, ok := c.entries["list:3"]; ok || len(c.tags["statements"]) != 1 {
+		t.Errorf("entries %v, statements tag %v after the sweep, want the expired entry gone", c.entries, c.tags["statements"])
+	}
+}
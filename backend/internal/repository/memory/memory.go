@@ -0,0 +1,113 @@
+// Package memory provides thread-safe in-memory implementations of the storage and cache interfaces.
+// Nothing survives a restart: they are meant for tests and local runs without PostgreSQL and Redis.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"hack/internal/models"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Storage keeps statements, their history, users and sessions in memory.
+// It behaves like postgres.Storage: every change is recorded in the history and the outbox atomically.
+type Storage struct {
+	mu sync.RWMutex
+
+	statements  map[int]models.Statement
+	transitions []models.StatusTransition
+	events      []models.StatementEvent
+	outbox      []models.OutboxMessage
+	keys        map[string]models.IdempotencyKey
+	users       map[int64]models.User
+	sessions    map[string]models.Session
+
+	lastStatementID  int
+	lastTransitionID int64
+	lastEventID      int64
+	lastOutboxID     int64
+	lastUserID       int64
+}
+
+// New creates an empty storage.
+func New() *Storage {
+	return &Storage{
+		statements: make(map[int]models.Statement),
+		keys:       make(map[string]models.IdempotencyKey),
+		users:      make(map[int64]models.User),
+		sessions:   make(map[string]models.Session),
+	}
+}
+
+// addEvent records the event and puts it into the outbox, like insertEvent of postgres.Storage.
+// statement is the state after the change, or the last state for deletions. The caller holds the lock.
+func (s *Storage) addEvent(event models.StatementEvent, statement models.Statement) error {
+	s.lastEventID++
+	event.ID = s.lastEventID
+	event.CreatedAt = time.Now()
+
+	envelope, err := models.NewEnvelope(event, statement)
+	if err != nil {
+		return fmt.Errorf("outbox envelope: %w", err)
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("marshal outbox payload: %w", err)
+	}
+
+	s.events = append(s.events, event)
+	s.lastOutboxID++
+	s.outbox = append(s.outbox, models.OutboxMessage{
+		ID:        s.lastOutboxID,
+		Topic:     models.OutboxEvents,
+		Key:       strconv.Itoa(event.StatementID),
+		Payload:   payload,
+		CreatedAt: event.CreatedAt,
+	})
+
+	return nil
+}
+
+// sortedStatements returns the statements matching the filter ordered by id. The caller holds the lock.
+func (s *Storage) sortedStatements(filter models.StatementFilter) []models.Statement {
+	statements := make([]models.Statement, 0, len(s.statements))
+	for _, stmt := range s.statements {
+		if matchFilter(filter, stmt) {
+			statements = append(statements, stmt)
+		}
+	}
+	slices.SortFunc(statements, func(a, b models.Statement) int { return a.StatementUID - b.StatementUID })
+	return statements
+}
+
+// matchFilter reports whether the statement passes the filter, like filterConditions of postgres.Storage.
+func matchFilter(f models.StatementFilter, stmt models.Statement) bool {
+	if len(f.Districts) > 0 && !slices.Contains(f.Districts, stmt.District) {
+		return false
+	}
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, stmt.Category) {
+		return false
+	}
+	if len(f.Subcategories) > 0 && !slices.Contains(f.Subcategories, stmt.Subcategory) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, stmt.Status) {
+		return false
+	}
+	if len(f.Sources) > 0 && !slices.Contains(f.Sources, stmt.Source) {
+		return false
+	}
+	if f.AdminStatus != nil && stmt.AdminStatus != *f.AdminStatus {
+		return false
+	}
+	if !f.From.IsZero() && stmt.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !stmt.CreatedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
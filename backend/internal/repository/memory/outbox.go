@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hack/internal/models"
+	"time"
+)
+
+// EnqueueOutbox puts the message into the outbox.
+func (s *Storage) EnqueueOutbox(_ context.Context, msg models.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastOutboxID++
+	msg.ID = s.lastOutboxID
+	msg.Payload = append([]byte(nil), msg.Payload...)
+	msg.CreatedAt = time.Now()
+	s.outbox = append(s.outbox, msg)
+
+	return nil
+}
+
+// PublishOutbox passes up to limit oldest outbox messages of the topic to publish in order and removes the published ones.
+// Publishing stops on the first error, the rest stay in the outbox until the next call.
+// The storage is locked meanwhile, so concurrent relays never publish one message twice.
+func (s *Storage) PublishOutbox(ctx context.Context, topic string, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error) {
+	const op = "storage.memory.PublishOutbox"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	published := 0
+	var publishErr error
+	remaining := s.outbox[:0]
+	for _, msg := range s.outbox {
+		if msg.Topic != topic || published == limit || publishErr != nil {
+			remaining = append(remaining, msg)
+			continue
+		}
+		if err := publish(ctx, msg); err != nil {
+			publishErr = fmt.Errorf("%s: publish message (id=%d): %w", op, msg.ID, err)
+			remaining = append(remaining, msg)
+			continue
+		}
+		published++
+	}
+	s.outbox = remaining
+
+	return published, publishErr
+}
+
+// ReserveIdempotencyKey stores the key with the result returned on a replay.
+// If the key exists, the saved key is returned with false.
+func (s *Storage) ReserveIdempotencyKey(_ context.Context, key models.IdempotencyKey) (models.IdempotencyKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.keys[key.Key]; ok {
+		return stored, false, nil
+	}
+	key.CreatedAt = time.Now()
+	s.keys[key.Key] = key
+
+	return key, true, nil
+}
+
+// CompleteIdempotencyKey marks the request with the key finished.
+func (s *Storage) CompleteIdempotencyKey(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[key]
+	if ok && stored.CompletedAt == nil {
+		now := time.Now()
+		stored.CompletedAt = &now
+		s.keys[key] = stored
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys removes the keys expired by now and returns their number.
+func (s *Storage) PurgeIdempotencyKeys(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for k, key := range s.keys {
+		if !key.ExpiresAt.After(now) {
+			delete(s.keys, k)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,434 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hack/internal/models"
+	"hack/internal/repository"
+	"html"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// NewStatement stores the statements and records a creation event for each of them.
+// If the idempotency key has been processed already, nothing is stored and the saved key is returned with false.
+func (s *Storage) NewStatement(_ context.Context, key models.IdempotencyKey, statements []models.Statement, actorID int64) (models.IdempotencyKey, bool, error) {
+	const op = "storage.memory.NewStatement"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.keys[key.Key]; ok {
+		return stored, false, nil
+	}
+
+	now := time.Now()
+	result := models.SubmissionResult{StatementIDs: make([]int, 0, len(statements))}
+	created := make([]models.Statement, 0, len(statements))
+	for _, stmt := range statements {
+		if stmt.CreatedAt.IsZero() {
+			stmt.CreatedAt = now
+		}
+		stmt.StatementUID = s.lastStatementID + len(created) + 1
+		stmt.UpdatedAt = now
+		stmt.ResolvedAt = nil
+		if stmt.Status == models.StatusResolved {
+			stmt.ResolvedAt = &now
+		}
+		created = append(created, stmt)
+		result.StatementIDs = append(result.StatementIDs, stmt.StatementUID)
+	}
+
+	var err error
+	key.Result, err = json.Marshal(result)
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: marshal result: %w", op, err)
+	}
+	key.CreatedAt = now
+	key.CompletedAt = &now
+
+	for _, stmt := range created {
+		s.statements[stmt.StatementUID] = stmt
+		err := s.addEvent(models.StatementEvent{
+			StatementID: stmt.StatementUID,
+			Type:        models.EventCreated,
+			ActorID:     actorID,
+			Changes:     models.DiffStatements(nil, &stmt),
+		}, stmt)
+		if err != nil {
+			return models.IdempotencyKey{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	s.lastStatementID += len(created)
+	s.keys[key.Key] = key
+
+	return key, true, nil
+}
+
+// GetStatement returns the statement by id.
+func (s *Storage) GetStatement(id int) (models.Statement, error) {
+	const op = "storage.memory.GetStatement"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stmt, ok := s.statements[id]
+	if !ok {
+		return models.Statement{}, fmt.Errorf("%s: statement (id=%d): %w", op, id, repository.ErrNotFound)
+	}
+	return stmt, nil
+}
+
+// DeleteStatement removes the statement keeping its last state in the history.
+func (s *Storage) DeleteStatement(id int, actorID int64) error {
+	const op = "storage.memory.DeleteStatement"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.statements[id]
+	if !ok {
+		return fmt.Errorf("%s: statement (id=%d): %w", op, id, repository.ErrNotFound)
+	}
+
+	err := s.addEvent(models.StatementEvent{
+		StatementID: id,
+		Type:        models.EventDeleted,
+		ActorID:     actorID,
+		Changes:     models.DiffStatements(&current, nil),
+	}, current)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	delete(s.statements, id)
+
+	return nil
+}
+
+// UpdateStatement saves new values of the statements and records the changed fields in the history.
+// A change of admin_status alone is recorded as a moderation decision.
+// A settled category suggestion is dropped, see models.SuggestionSettled.
+// Nothing is saved when any of the statements does not exist.
+func (s *Storage) UpdateStatement(_ context.Context, statements []models.Statement, actorID int64) error {
+	const op = "storage.memory.UpdateStatement"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stmt := range statements {
+		if _, ok := s.statements[stmt.StatementUID]; !ok {
+			return fmt.Errorf("%s: statement (id=%d): %w", op, stmt.StatementUID, repository.ErrNotFound)
+		}
+	}
+
+	for _, stmt := range statements {
+		current := s.statements[stmt.StatementUID]
+		settled := models.SuggestionSettled(&current, &stmt)
+		changes := models.DiffStatements(&current, &stmt)
+		if len(changes) == 0 && !settled {
+			continue
+		}
+
+		updated := current
+		if settled {
+			updated.Suggestion = nil
+		}
+		updated.Source = stmt.Source
+		updated.District = stmt.District
+		updated.Category = stmt.Category
+		updated.Subcategory = stmt.Subcategory
+		updated.Status = stmt.Status
+		updated.AdminStatus = stmt.AdminStatus
+		updated.Description = stmt.Description
+		updated.UpdatedAt = time.Now()
+		if len(changes) == 0 {
+			s.statements[stmt.StatementUID] = updated
+			continue
+		}
+
+		eventType := models.EventUpdated
+		if _, ok := changes["admin_status"]; ok && len(changes) == 1 {
+			eventType = models.EventModerated
+		}
+
+		err := s.addEvent(models.StatementEvent{
+			StatementID: stmt.StatementUID,
+			Type:        eventType,
+			ActorID:     actorID,
+			Changes:     changes,
+		}, updated)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		s.statements[stmt.StatementUID] = updated
+	}
+
+	return nil
+}
+
+// ChangeStatus sets the new status and records the transition.
+// Resolving sets resolved_at, reopening clears it.
+// The status is only changed when the statement still has transition.From,
+// otherwise repository.ErrConflict is returned.
+func (s *Storage) ChangeStatus(_ context.Context, transition models.StatusTransition) error {
+	const op = "storage.memory.ChangeStatus"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated, ok := s.statements[transition.StatementID]
+	if !ok || updated.Status != transition.From {
+		return fmt.Errorf("%s: statement (id=%d) is no longer %q: %w",
+			op, transition.StatementID, transition.From, repository.ErrConflict)
+	}
+
+	now := time.Now()
+	updated.Status = transition.To
+	updated.UpdatedAt = now
+	switch transition.To {
+	case models.StatusResolved:
+		updated.ResolvedAt = &now
+	case models.StatusReopened:
+		updated.ResolvedAt = nil
+	}
+
+	err := s.addEvent(models.StatementEvent{
+		StatementID: transition.StatementID,
+		Type:        models.EventStatusChanged,
+		ActorID:     transition.ActorID,
+		Changes: map[string]models.FieldChange{
+			"status": {Old: transition.From, New: transition.To},
+		},
+		Comment: transition.Reason,
+	}, updated)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.lastTransitionID++
+	transition.ID = s.lastTransitionID
+	transition.CreatedAt = now
+	s.transitions = append(s.transitions, transition)
+	s.statements[transition.StatementID] = updated
+
+	return nil
+}
+
+// GetStatusTransitions returns the status changes of the statement in chronological order.
+func (s *Storage) GetStatusTransitions(_ context.Context, statementID int) ([]models.StatusTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transitions := []models.StatusTransition{}
+	for _, t := range s.transitions {
+		if t.StatementID == statementID {
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions, nil
+}
+
+// GetStatementHistory returns all events of the statement in chronological order.
+func (s *Storage) GetStatementHistory(_ context.Context, statementID int) ([]models.StatementEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []models.StatementEvent{}
+	for _, e := range s.events {
+		if e.StatementID == statementID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// ListStatements returns a page of statements matching the filter with keyset pagination
+// and the total number of matching statements.
+func (s *Storage) ListStatements(_ context.Context, q models.StatementQuery) ([]models.Statement, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statements := s.sortedStatements(q.Filter)
+	total := len(statements)
+
+	sortValue := func(stmt models.Statement) time.Time {
+		switch q.SortBy {
+		case models.SortByCreatedAt:
+			return stmt.CreatedAt
+		case models.SortByUpdatedAt:
+			return stmt.UpdatedAt
+		}
+		return time.Time{}
+	}
+	// compare orders by the sort value, then by id, like ORDER BY column, id.
+	compare := func(v time.Time, id int, stmt models.Statement) int {
+		if c := v.Compare(sortValue(stmt)); c != 0 {
+			return c
+		}
+		return id - stmt.StatementUID
+	}
+	slices.SortStableFunc(statements, func(a, b models.Statement) int {
+		c := compare(sortValue(a), a.StatementUID, b)
+		if q.Desc {
+			return -c
+		}
+		return c
+	})
+
+	page := []models.Statement{}
+	for _, stmt := range statements {
+		if q.After != nil {
+			c := compare(sortValue(stmt), stmt.StatementUID, models.Statement{
+				StatementUID: q.After.ID,
+				CreatedAt:    q.After.SortValue,
+				UpdatedAt:    q.After.SortValue,
+			})
+			if (!q.Desc && c <= 0) || (q.Desc && c >= 0) {
+				continue
+			}
+		}
+		if len(page) == q.Limit {
+			break
+		}
+		page = append(page, stmt)
+	}
+
+	return page, total, nil
+}
+
+// SearchStatements finds statements whose subcategory or description contains every word of the text.
+// Words match by prefix, which roughly stands in for the Russian morphology of full-text search.
+// Hits are ordered by rank with the subcategory weighing more than the description.
+func (s *Storage) SearchStatements(_ context.Context, q models.SearchQuery) ([]models.SearchHit, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	terms := searchTerms(q.Text)
+	hits := []models.SearchHit{}
+	for _, stmt := range s.sortedStatements(q.Filter) {
+		rank, ok := searchRank(terms, stmt)
+		if !ok {
+			continue
+		}
+		hits = append(hits, models.SearchHit{
+			Statement: stmt,
+			Rank:      rank,
+			Snippet:   highlight(stmt.Description, terms),
+		})
+	}
+	slices.SortStableFunc(hits, func(a, b models.SearchHit) int {
+		if a.Rank != b.Rank {
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		}
+		return b.Statement.StatementUID - a.Statement.StatementUID
+	})
+
+	total := len(hits)
+	start := min(q.Offset, total)
+	end := total
+	if q.Limit > 0 {
+		end = min(start+q.Limit, total)
+	}
+	return hits[start:end], total, nil
+}
+
+// GetRecomendatonsContext returns up to 100 latest statements matching the filter.
+func (s *Storage) GetRecomendatonsContext(_ context.Context, filter models.StatementFilter) ([]models.Statement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statements := s.sortedStatements(filter)
+	slices.SortStableFunc(statements, func(a, b models.Statement) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if len(statements) > 100 {
+		statements = statements[:100]
+	}
+	return statements, nil
+}
+
+// searchTerms splits the text into lowercase word stems.
+func searchTerms(text string) []string {
+	words := splitWords(strings.ToLower(text))
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if w.word {
+			terms = append(terms, stem(w.text))
+		}
+	}
+	return terms
+}
+
+// stem cuts the ending off a word: up to two runes, keeping at least three.
+func stem(word string) string {
+	runes := []rune(word)
+	cut := min(2, max(len(runes)-3, 0))
+	return string(runes[:len(runes)-cut])
+}
+
+// searchRank reports whether every term occurs in the statement and ranks the match.
+func searchRank(terms []string, stmt models.Statement) (float64, bool) {
+	if len(terms) == 0 {
+		return 0, false
+	}
+	var rank float64
+	for _, term := range terms {
+		inSubcategory := countMatches(stmt.Subcategory, term)
+		inDescription := countMatches(stmt.Description, term)
+		if inSubcategory == 0 && inDescription == 0 {
+			return 0, false
+		}
+		rank += float64(inSubcategory) + 0.4*float64(inDescription)
+	}
+	return rank, true
+}
+
+func countMatches(text, term string) int {
+	n := 0
+	for _, w := range splitWords(strings.ToLower(text)) {
+		if w.word && strings.HasPrefix(w.text, term) {
+			n++
+		}
+	}
+	return n
+}
+
+// highlight HTML-escapes the text and wraps the words matching any term in <mark>.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	for _, w := range splitWords(text) {
+		lower := strings.ToLower(w.text)
+		matched := w.word && slices.ContainsFunc(terms, func(term string) bool { return strings.HasPrefix(lower, term) })
+		if matched {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(w.text))
+		if matched {
+			b.WriteString("</mark>")
+		}
+	}
+	return b.String()
+}
+
+// segment is a run of letters and digits (a word) or of other characters.
+type segment struct {
+	text string
+	word bool
+}
+
+func splitWords(text string) []segment {
+	var segments []segment
+	for _, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if n := len(segments); n > 0 && segments[n-1].word == word {
+			segments[n-1].text += string(r)
+			continue
+		}
+		segments = append(segments, segment{text: string(r), word: word})
+	}
+	return segments
+}
@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+func testStatement(i int) models.Statement {
+	return models.Statement{
+		Source:      "portal",
+		District:    "Центральный",
+		Category:    "Мусор",
+		Subcategory: "Переполненные контейнеры",
+		CreatedAt:   time.Date(2023, 12, 9, 10, i%3, 0, 0, time.UTC),
+		Status:      models.StatusNew,
+		Description: fmt.Sprintf("Контейнеры не вывозят, заявка %d", i),
+	}
+}
+
+func TestStorage_NewStatementConcurrent(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every key is sent twice, like a redelivered message.
+			key := models.IdempotencyKey{Key: fmt.Sprintf("k%d", i%10)}
+			if _, _, err := s.NewStatement(ctx, key, []models.Statement{testStatement(i)}, 0); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(s.statements) != 10 || len(s.outbox) != 10 {
+		t.Errorf("stored %d statements and %d outbox messages, want 10", len(s.statements), len(s.outbox))
+	}
+}
+
+func TestStorage_NewStatementResolved(t *testing.T) {
+	s := New()
+
+	resolved := testStatement(0)
+	resolved.Status = models.StatusResolved
+	key := models.IdempotencyKey{Key: "k"}
+	if _, _, err := s.NewStatement(context.Background(), key, []models.Statement{testStatement(1), resolved}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.statements[1].ResolvedAt != nil || s.statements[2].ResolvedAt == nil {
+		t.Errorf("resolved_at = %v, %v, want only the resolved statement to have it", s.statements[1].ResolvedAt, s.statements[2].ResolvedAt)
+	}
+}
+
+func TestStorage_UpdateStatementSettlesSuggestion(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	statements := []models.Statement{testStatement(0), testStatement(1), testStatement(2)}
+	if _, _, err := s.NewStatement(ctx, models.IdempotencyKey{Key: "k"}, statements, 0); err != nil {
+		t.Fatal(err)
+	}
+	suggestion := &models.CategorySuggestion{Category: "Дороги", Subcategory: "Ямы", Source: models.SuggestionLocal}
+	for id := 1; id <= 3; id++ {
+		stmt := s.statements[id]
+		stmt.Suggestion = suggestion
+		s.statements[id] = stmt
+	}
+
+	accepted := s.statements[1]
+	accepted.Category, accepted.Subcategory = "Дороги", "Ямы"
+	chosen := s.statements[2]
+	chosen.Subcategory = "Контейнерные площадки"
+	approved := s.statements[3]
+	approved.AdminStatus = false
+	if err := s.UpdateStatement(ctx, []models.Statement{accepted, chosen, approved}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.statements[1].Suggestion != nil || s.statements[2].Suggestion != nil {
+		t.Errorf("suggestions after accepting and choosing a category = %+v, %+v, want none", s.statements[1].Suggestion, s.statements[2].Suggestion)
+	}
+	if s.statements[3].Suggestion == nil {
+		t.Error("suggestion is dropped by a change not touching the category")
+	}
+}
+
+func TestStorage_PurgeIdempotencyKeys(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, key := range []models.IdempotencyKey{
+		{Key: "expired", ExpiresAt: now.Add(-time.Minute)},
+		{Key: "live", ExpiresAt: now.Add(time.Hour)},
+	} {
+		if _, _, err := s.ReserveIdempotencyKey(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged, err := s.PurgeIdempotencyKeys(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.keys["live"]; purged != 1 || len(s.keys) != 1 || !ok {
+		t.Errorf("purged %d keys, left %v, want only the live key left", purged, s.keys)
+	}
+}
+
+func TestStorage_GetResolutionAnalitic(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	imported := testStatement(0)
+	imported.Status = models.StatusResolved
+	inWork := testStatement(1)
+	inWork.Status = models.StatusInWork
+	key := models.IdempotencyKey{Key: "k"}
+	if _, _, err := s.NewStatement(ctx, key, []models.Statement{imported, inWork, testStatement(2)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ChangeStatus(ctx, models.StatusTransition{StatementID: 2, From: models.StatusInWork, To: models.StatusResolved}); err != nil {
+		t.Fatal(err)
+	}
+
+	total, _, err := s.GetResolutionAnalitic(ctx, models.ResolutionQuery{SLA: time.Hour}, []time.Duration{time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Resolved != 2 || total.Open != 1 {
+		t.Errorf("resolved %d, open %d, want 2 and 1: statements created resolved count too", total.Resolved, total.Open)
+	}
+}
+
+func TestStorage_ListStatements(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	var statements []models.Statement
+	for i := range 7 {
+		statements = append(statements, testStatement(i))
+	}
+	if _, _, err := s.NewStatement(ctx, models.IdempotencyKey{Key: "k"}, statements, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, desc := range []bool{false, true} {
+		q := models.StatementQuery{SortBy: models.SortByCreatedAt, Desc: desc, Limit: 3}
+		var ids []int
+		for {
+			page, total, err := s.ListStatements(ctx, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 7 {
+				t.Fatalf("total = %d, want 7", total)
+			}
+			for _, stmt := range page {
+				ids = append(ids, stmt.StatementUID)
+			}
+			if len(page) < q.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			q.After = &models.PageCursor{SortBy: q.SortBy, Desc: desc, SortValue: last.CreatedAt, ID: last.StatementUID}
+		}
+
+		// Minutes 0, 1, 2 repeat, so equal created_at values are ordered by id.
+		want := []int{1, 4, 7, 2, 5, 3, 6}
+		if desc {
+			want = []int{6, 3, 5, 2, 7, 4, 1}
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Errorf("desc=%v: ids = %v, want %v", desc, ids, want)
+		}
+	}
+}
+
+func TestStorage_SearchStatements(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	road := testStatement(0)
+	road.Subcategory = "Ямы"
+	road.Description = "Глубокая яма <на> дороге у дома"
+	if _, _, err := s.NewStatement(ctx, models.IdempotencyKey{Key: "k"}, []models.Statement{testStatement(1), road}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, total, err := s.SearchStatements(ctx, models.SearchQuery{Text: "дороги", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || hits[0].Statement.StatementUID != 2 {
+		t.Fatalf("hits = %+v, want the road statement", hits)
+	}
+	if want := "Глубокая яма &lt;на&gt; <mark>дороге</mark> у дома"; hits[0].Snippet != want {
+		t.Errorf("snippet = %q, want %q", hits[0].Snippet, want)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{0.5, 2.5},
+		{0.9, 3.7},
+		{1, 4},
+	}
+	for _, tt := range tests {
+		if got := percentile(values, tt.p); fmt.Sprintf("%.6f", got) != fmt.Sprintf("%.6f", tt.want) {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
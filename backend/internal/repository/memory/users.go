@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hack/internal/models"
+	"hack/internal/repository"
+	"slices"
+	"time"
+)
+
+// CreateUser stores a new user and returns its id.
+func (s *Storage) CreateUser(_ context.Context, user models.User) (int64, error) {
+	const op = "storage.memory.CreateUser"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == user.Username {
+			return 0, fmt.Errorf("%s: user %q: %w", op, user.Username, repository.ErrAlreadyExists)
+		}
+	}
+
+	s.lastUserID++
+	user.ID = s.lastUserID
+	user.CreatedAt = time.Now()
+	s.users[user.ID] = user
+
+	return user.ID, nil
+}
+
+// GetUserByUsername returns the user by login.
+func (s *Storage) GetUserByUsername(_ context.Context, username string) (models.User, error) {
+	const op = "storage.memory.GetUserByUsername"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return models.User{}, fmt.Errorf("%s: user %q: %w", op, username, repository.ErrNotFound)
+}
+
+// GetUser returns the user by id.
+func (s *Storage) GetUser(_ context.Context, id int64) (models.User, error) {
+	const op = "storage.memory.GetUser"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return models.User{}, fmt.Errorf("%s: user (id=%d): %w", op, id, repository.ErrNotFound)
+	}
+	return user, nil
+}
+
+// ListUsers returns all users ordered by id.
+func (s *Storage) ListUsers(_ context.Context) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]models.User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	slices.SortFunc(users, func(a, b models.User) int { return int(a.ID - b.ID) })
+
+	return users, nil
+}
+
+// UpdateUserRole changes the role of the user.
+func (s *Storage) UpdateUserRole(_ context.Context, id int64, role models.Role) error {
+	const op = "storage.memory.UpdateUserRole"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return fmt.Errorf("%s: user (id=%d): %w", op, id, repository.ErrNotFound)
+	}
+	user.Role = role
+	s.users[id] = user
+
+	return nil
+}
+
+// CreateSession stores a new session. Expired sessions of the same user are removed.
+func (s *Storage) CreateSession(_ context.Context, session models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, existing := range s.sessions {
+		if existing.UserID == session.UserID && !existing.ExpiresAt.After(now) {
+			delete(s.sessions, hash)
+		}
+	}
+
+	session.CreatedAt = now
+	s.sessions[session.TokenHash] = session
+
+	return nil
+}
+
+// GetSession returns a non-expired session by its token hash.
+func (s *Storage) GetSession(_ context.Context, tokenHash string) (models.Session, error) {
+	const op = "storage.memory.GetSession"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[tokenHash]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return models.Session{}, fmt.Errorf("%s: session: %w", op, repository.ErrNotFound)
+	}
+	return session, nil
+}
+
+// DeleteSession removes a session. Deleting a missing session is not an error.
+func (s *Storage) DeleteSession(_ context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, tokenHash)
+
+	return nil
+}
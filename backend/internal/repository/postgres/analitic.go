@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hack/internal/models"
+	"sort"
+	"time"
+)
+
+// cityTimeZone is the zone used to cut statements into days, weeks and months.
+// It matches models.CityLocation.
+const cityTimeZone = "Europe/Moscow"
+
+// GetPeriodAnalitic считает заявки по интервалам (день, неделя, месяц) с учетом фильтра.
+// Возвращаются только непустые интервалы в хронологическом порядке.
+func (s *Storage) GetPeriodAnalitic(ctx context.Context, filter models.StatementFilter, bucket models.Bucket) ([]models.PeriodPoint, error) {
+	const op = "storage.postgres.GetPeriodAnalitic"
+
+	args := []any{string(bucket), cityTimeZone}
+	where, args := filterConditions(filter, args)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+		date_trunc($1, created_at, $2) AS bucket, COUNT(*)
+		FROM statements
+		WHERE `+where+`
+		GROUP BY bucket
+		ORDER BY bucket`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	points := []models.PeriodPoint{}
+	for rows.Next() {
+		var point models.PeriodPoint
+		if err := rows.Scan(&point.Start, &point.Count); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return points, nil
+}
+
+// GetCategoriesAnalitic считает заявки по категориям с учетом фильтра.
+func (s *Storage) GetCategoriesAnalitic(ctx context.Context, filter models.StatementFilter) (map[string]int, error) {
+	const op = "storage.postgres.GetCategoriesAnalitic"
+
+	where, args := filterConditions(filter, nil)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+		category, COUNT(*)
+		FROM statements
+		WHERE `+where+`
+		GROUP BY category`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	analitic := make(map[string]int)
+	for rows.Next() {
+		key, value := "", 0
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		analitic[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return analitic, nil
+}
+
+// GetCrossTabAnalitic считает заявки по парам (район, категория) одним запросом с учетом фильтра.
+func (s *Storage) GetCrossTabAnalitic(ctx context.Context, filter models.StatementFilter) ([]models.CrossTabCell, error) {
+	const op = "storage.postgres.GetCrossTabAnalitic"
+
+	where, args := filterConditions(filter, nil)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+		district, category, COUNT(*)
+		FROM statements
+		WHERE `+where+`
+		GROUP BY district, category`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	cells := []models.CrossTabCell{}
+	for rows.Next() {
+		var cell models.CrossTabCell
+		if err := rows.Scan(&cell.District, &cell.Category, &cell.Count); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		cells = append(cells, cell)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return cells, nil
+}
+
+// GetResolutionAnalitic считает время решения и возраст открытых заявок, подходящих под фильтр:
+// итог и разбивку по q.GroupBy. Время решения — resolved_at: последний переход в статус «Решено»
+// или, для заявок, созданных решенными, время их сохранения. Backlog заполняется счетчиками
+// по интервалам возраста, заданным границами ageBounds.
+func (s *Storage) GetResolutionAnalitic(ctx context.Context, q models.ResolutionQuery, ageBounds []time.Duration) (models.ResolutionStats, []models.ResolutionStats, error) {
+	const op = "storage.postgres.GetResolutionAnalitic"
+
+	// q.GroupBy is validated by the use case, so it is safe to put into the query.
+	isTotal, key, sets := "TRUE", "''", "()"
+	if q.GroupBy != "" {
+		column := string(q.GroupBy)
+		isTotal = "GROUPING(" + column + ") = 1"
+		key = "COALESCE(" + column + ", '')"
+		sets = "(), (" + column + ")"
+	}
+
+	groups := make(map[string]*models.ResolutionStats)
+	var total *models.ResolutionStats
+	stats := func(grand bool, k string) *models.ResolutionStats {
+		if grand {
+			if total == nil {
+				total = &models.ResolutionStats{Backlog: make([]models.AgeBucket, len(ageBounds)+1)}
+			}
+			return total
+		}
+		if groups[k] == nil {
+			groups[k] = &models.ResolutionStats{Key: k, Backlog: make([]models.AgeBucket, len(ageBounds)+1)}
+		}
+		return groups[k]
+	}
+
+	where, args := filterConditions(q.Filter, []any{string(models.StatusResolved), q.SLA.Seconds()})
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH resolved AS (
+			SELECT
+			district, category, source,
+			EXTRACT(EPOCH FROM resolved_at - created_at)::float8 AS seconds
+			FROM statements
+			WHERE status = $1 AND resolved_at IS NOT NULL AND `+where+`
+		)
+		SELECT
+		`+isTotal+`, `+key+`, COUNT(*),
+		percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds),
+		percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds),
+		AVG(seconds),
+		COUNT(*) FILTER (WHERE seconds <= $2)
+		FROM resolved
+		GROUP BY GROUPING SETS (`+sets+`)`,
+		args...,
+	)
+	if err != nil {
+		return models.ResolutionStats{}, nil, fmt.Errorf("%s: query resolved: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			grand             bool
+			k                 string
+			resolved, inTime  int
+			median, p90, mean sql.NullFloat64
+		)
+		if err := rows.Scan(&grand, &k, &resolved, &median, &p90, &mean, &inTime); err != nil {
+			return models.ResolutionStats{}, nil, fmt.Errorf("%s: scan resolved: %w", op, err)
+		}
+		st := stats(grand, k)
+		st.Resolved = resolved
+		st.MedianHours = median.Float64 / 3600
+		st.P90Hours = p90.Float64 / 3600
+		st.MeanHours = mean.Float64 / 3600
+		st.WithinSLA = inTime
+	}
+	if err := rows.Err(); err != nil {
+		return models.ResolutionStats{}, nil, fmt.Errorf("%s: rows resolved: %w", op, err)
+	}
+
+	bounds := make([]float64, len(ageBounds))
+	for i, b := range ageBounds {
+		bounds[i] = b.Seconds()
+	}
+	closed := []string{string(models.StatusResolved), string(models.StatusRejected)}
+
+	// width_bucket с массивом границ возвращает 0 для возраста меньше первой границы
+	// и len(bounds) для возраста не меньше последней.
+	where, args = filterConditions(q.Filter, []any{closed, bounds, q.SLA.Seconds()})
+	sets = "(age_bucket)"
+	if q.GroupBy != "" {
+		sets = "(age_bucket), (" + string(q.GroupBy) + ", age_bucket)"
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		WITH backlog AS (
+			SELECT
+			district, category, source,
+			EXTRACT(EPOCH FROM NOW() - created_at)::float8 AS seconds,
+			width_bucket(EXTRACT(EPOCH FROM NOW() - created_at)::float8, $2::float8[]) AS age_bucket
+			FROM statements
+			WHERE status <> ALL($1) AND `+where+`
+		)
+		SELECT
+		`+isTotal+`, `+key+`, age_bucket, COUNT(*),
+		COUNT(*) FILTER (WHERE seconds > $3)
+		FROM backlog
+		GROUP BY GROUPING SETS (`+sets+`)`,
+		args...,
+	)
+	if err != nil {
+		return models.ResolutionStats{}, nil, fmt.Errorf("%s: query backlog: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			grand                  bool
+			k                      string
+			bucket, count, overdue int
+		)
+		if err := rows.Scan(&grand, &k, &bucket, &count, &overdue); err != nil {
+			return models.ResolutionStats{}, nil, fmt.Errorf("%s: scan backlog: %w", op, err)
+		}
+		st := stats(grand, k)
+		st.Open += count
+		st.Overdue += overdue
+		st.Backlog[bucket].Count += count
+	}
+	if err := rows.Err(); err != nil {
+		return models.ResolutionStats{}, nil, fmt.Errorf("%s: rows backlog: %w", op, err)
+	}
+
+	result := make([]models.ResolutionStats, 0, len(groups))
+	for _, st := range groups {
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	return *stats(true, ""), result, nil
+}
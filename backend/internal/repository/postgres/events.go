@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hack/internal/models"
+	"hack/internal/repository"
+)
+
+// insertEvent writes an audit record inside the transaction of the change it describes
+// and puts the event into the outbox, so it is published to Kafka if and only if the change is committed.
+// statement is the state after the change, or the last state for deletions.
+func insertEvent(ctx context.Context, tx *sql.Tx, event models.StatementEvent, statement models.Statement) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("marshal changes: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO statement_events (
+		statement_id, event_type, actor_id, changes, comment
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		event.StatementID,
+		event.Type,
+		sql.NullInt64{Int64: event.ActorID, Valid: event.ActorID != 0},
+		changes,
+		event.Comment,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert event: %w", err)
+	}
+
+	if err := insertOutbox(ctx, tx, event, statement); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getStatementForUpdate reads a statement and locks its row until the transaction ends.
+func getStatementForUpdate(ctx context.Context, tx *sql.Tx, id int) (models.Statement, error) {
+	stmt, err := scanStatement(tx.QueryRowContext(ctx, `
+		SELECT `+statementColumns+`
+		FROM statements
+		WHERE id = $1
+		FOR UPDATE`,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Statement{}, fmt.Errorf("statement (id=%d): %w", id, repository.ErrNotFound)
+		}
+		return models.Statement{}, fmt.Errorf("select statement: %w", err)
+	}
+
+	return stmt, nil
+}
+
+// GetStatementHistory возвращает все события заявки в хронологическом порядке
+func (s *Storage) GetStatementHistory(ctx context.Context, statementID int) ([]models.StatementEvent, error) {
+	const op = "storage.postgres.GetStatementHistory"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, statement_id, event_type, actor_id, changes, comment, created_at
+		FROM statement_events
+		WHERE statement_id = $1
+		ORDER BY created_at, id`,
+		statementID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := []models.StatementEvent{}
+	for rows.Next() {
+		var (
+			event   models.StatementEvent
+			actorID sql.NullInt64
+			changes []byte
+		)
+		if err := rows.Scan(&event.ID, &event.StatementID, &event.Type, &actorID, &changes, &event.Comment, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, fmt.Errorf("%s: unmarshal changes: %w", op, err)
+		}
+		event.ActorID = actorID.Int64
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"hack/internal/models"
+	"strings"
+)
+
+// filterConditions returns SQL conditions for the filter joined with AND.
+// Placeholders continue numbering after the existing args.
+func filterConditions(f models.StatementFilter, args []any) (string, []any) {
+	conds := []string{"TRUE"}
+
+	add := func(cond string, value any) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if len(f.Districts) > 0 {
+		add("district = ANY($%d)", f.Districts)
+	}
+	if len(f.Categories) > 0 {
+		add("category = ANY($%d)", f.Categories)
+	}
+	if len(f.Subcategories) > 0 {
+		add("subcategory = ANY($%d)", f.Subcategories)
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, status := range f.Statuses {
+			statuses[i] = string(status)
+		}
+		add("status = ANY($%d)", statuses)
+	}
+	if len(f.Sources) > 0 {
+		add("source = ANY($%d)", f.Sources)
+	}
+	if f.AdminStatus != nil {
+		add("admin_status = $%d", *f.AdminStatus)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// ListStatements возвращает страницу заявок по фильтру с keyset-пагинацией
+// и общее число заявок, подходящих под фильтр.
+func (s *Storage) ListStatements(ctx context.Context, q models.StatementQuery) ([]models.Statement, int, error) {
+	const op = "storage.postgres.ListStatements"
+
+	where, args := filterConditions(q.Filter, nil)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM statements
+		WHERE `+where,
+		args...,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: count: %w", op, err)
+	}
+
+	// q.SortBy is validated by the use case, so it is safe to put into the query.
+	column := string(q.SortBy)
+	cmp, order := ">", "ASC"
+	if q.Desc {
+		cmp, order = "<", "DESC"
+	}
+
+	if q.After != nil {
+		if q.SortBy == models.SortByID {
+			args = append(args, q.After.ID)
+			where += fmt.Sprintf(" AND id %s $%d", cmp, len(args))
+		} else {
+			args = append(args, q.After.SortValue, q.After.ID)
+			where += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", column, cmp, len(args)-1, len(args))
+		}
+	}
+
+	orderBy := fmt.Sprintf("id %s", order)
+	if q.SortBy != models.SortByID {
+		orderBy = fmt.Sprintf("%s %s, id %s", column, order, order)
+	}
+
+	args = append(args, q.Limit)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+statementColumns+`
+		FROM statements
+		WHERE `+where+`
+		ORDER BY `+orderBy+fmt.Sprintf(`
+		LIMIT $%d`, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	statements, err := scanStatements(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return statements, total, nil
+}
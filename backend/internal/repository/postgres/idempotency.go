@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hack/internal/models"
+	"hack/internal/repository"
+	"time"
+)
+
+// ReserveIdempotencyKey сохраняет ключ с результатом, который вернется при повторе запроса.
+// Если ключ уже есть, возвращается сохраненная запись и false.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (models.IdempotencyKey, bool, error) {
+	const op = "storage.postgres.ReserveIdempotencyKey"
+
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash, result, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO NOTHING
+		RETURNING created_at`,
+		key.Key,
+		key.RequestHash,
+		key.Result,
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		stored, err := s.getIdempotencyKey(ctx, key.Key)
+		if err != nil {
+			return models.IdempotencyKey{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+		return stored, false, nil
+	}
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: insert: %w", op, err)
+	}
+
+	return key, true, nil
+}
+
+// CompleteIdempotencyKey отмечает запрос с ключом завершенным.
+func (s *Storage) CompleteIdempotencyKey(ctx context.Context, key string) error {
+	const op = "storage.postgres.CompleteIdempotencyKey"
+
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET completed_at = NOW()
+		WHERE key = $1 AND completed_at IS NULL`,
+		key,
+	); err != nil {
+		return fmt.Errorf("%s: update: %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys удаляет ключи, срок хранения которых истек к now, и возвращает их число.
+func (s *Storage) PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	const op = "storage.postgres.PurgeIdempotencyKeys"
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: delete: %w", op, err)
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+
+	return purged, nil
+}
+
+func (s *Storage) getIdempotencyKey(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	stored := models.IdempotencyKey{Key: key}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT request_hash, result, created_at, completed_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1`,
+		key,
+	).Scan(&stored.RequestHash, &stored.Result, &stored.CreatedAt, &stored.CompletedAt, &stored.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.IdempotencyKey{}, fmt.Errorf("get idempotency key: %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return models.IdempotencyKey{}, fmt.Errorf("get idempotency key: %w", err)
+	}
+	return stored, nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hack/internal/models"
+	"strconv"
+)
+
+// insertOutbox puts the event wrapped into models.Envelope into the outbox inside the transaction
+// of the change it describes. Events are keyed by statement id, so events of one statement
+// land in one Kafka partition.
+func insertOutbox(ctx context.Context, tx *sql.Tx, event models.StatementEvent, statement models.Statement) error {
+	envelope, err := models.NewEnvelope(event, statement)
+	if err != nil {
+		return fmt.Errorf("outbox envelope: %w", err)
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("marshal outbox payload: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox (topic, message_key, payload)
+		VALUES ($1, $2, $3)`,
+		models.OutboxEvents,
+		strconv.Itoa(event.StatementID),
+		payload,
+	); err != nil {
+		return fmt.Errorf("insert outbox: %w", err)
+	}
+
+	return nil
+}
+
+// EnqueueOutbox кладет сообщение в outbox вне транзакции изменения заявки.
+func (s *Storage) EnqueueOutbox(ctx context.Context, msg models.OutboxMessage) error {
+	const op = "storage.postgres.EnqueueOutbox"
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO outbox (topic, message_key, payload)
+		VALUES ($1, $2, $3)`,
+		msg.Topic,
+		msg.Key,
+		msg.Payload,
+	); err != nil {
+		return fmt.Errorf("%s: insert: %w", op, err)
+	}
+
+	return nil
+}
+
+// PublishOutbox передает publish до limit самых старых сообщений topic по порядку
+// и удаляет опубликованные. На первой ошибке публикация останавливается, остальные сообщения
+// остаются в outbox до следующего вызова. Выбранные строки заблокированы до конца транзакции,
+// поэтому несколько relay'ев не публикуют одно сообщение одновременно.
+func (s *Storage) PublishOutbox(ctx context.Context, topic string, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error) {
+	const op = "storage.postgres.PublishOutbox"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, topic, message_key, payload, created_at
+		FROM outbox
+		WHERE topic = $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`,
+		topic,
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: query: %w", op, err)
+	}
+
+	messages := []models.OutboxMessage{}
+	for rows.Next() {
+		var msg models.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.Topic, &msg.Key, &msg.Payload, &msg.CreatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		messages = append(messages, msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	published := make([]int64, 0, len(messages))
+	var publishErr error
+	for _, msg := range messages {
+		if publishErr = publish(ctx, msg); publishErr != nil {
+			break
+		}
+		published = append(published, msg.ID)
+	}
+
+	if len(published) > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM outbox
+			WHERE id = ANY($1)`,
+			published,
+		); err != nil {
+			return 0, fmt.Errorf("%s: delete published: %w", op, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("%s: commit: %w", op, err)
+		}
+	}
+
+	if publishErr != nil {
+		return len(published), fmt.Errorf("%s: publish message (id=%d): %w", op, messages[len(published)].ID, publishErr)
+	}
+
+	return len(published), nil
+}
@@ -5,9 +5,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hack/internal/models"
+	"hack/internal/repository"
 	"log/slog"
 	"os"
 	"time"
@@ -61,83 +63,200 @@ func runMigrations(db *sql.DB, migrationsPath string) error {
 	return nil
 }
 
-func (s *Storage) NewStatement(statements []models.Statement) error {
+// statementColumns is the column list read by scanStatement.
+const statementColumns = `id, source, district, category, subcategory,
+		created_at, updated_at, resolved_at, status, admin_status, description,
+		suggested_category, suggested_subcategory, suggestion_confidence, suggestion_source`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanStatement reads a row selected with statementColumns followed by the extra columns, if any.
+func scanStatement(row rowScanner, extra ...any) (models.Statement, error) {
+	var stmt models.Statement
+	var suggestedCategory, suggestedSubcategory, suggestionSource sql.NullString
+	var suggestionConfidence sql.NullFloat64
+	dest := []any{
+		&stmt.StatementUID,
+		&stmt.Source,
+		&stmt.District,
+		&stmt.Category,
+		&stmt.Subcategory,
+		&stmt.CreatedAt,
+		&stmt.UpdatedAt,
+		&stmt.ResolvedAt,
+		&stmt.Status,
+		&stmt.AdminStatus,
+		&stmt.Description,
+		&suggestedCategory,
+		&suggestedSubcategory,
+		&suggestionConfidence,
+		&suggestionSource,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if suggestedCategory.Valid {
+		stmt.Suggestion = &models.CategorySuggestion{
+			Category:    suggestedCategory.String,
+			Subcategory: suggestedSubcategory.String,
+			Confidence:  suggestionConfidence.Float64,
+			Source:      suggestionSource.String,
+		}
+	}
+	return stmt, err
+}
+
+// suggestionColumns returns the values of the suggestion columns, all NULL without a suggestion.
+func suggestionColumns(s *models.CategorySuggestion) (category, subcategory, source sql.NullString, confidence sql.NullFloat64) {
+	if s == nil {
+		return
+	}
+	return sql.NullString{String: s.Category, Valid: true},
+		sql.NullString{String: s.Subcategory, Valid: true},
+		sql.NullString{String: s.Source, Valid: true},
+		sql.NullFloat64{Float64: s.Confidence, Valid: true}
+}
+
+// scanStatements reads all rows selected with statementColumns.
+func scanStatements(rows *sql.Rows) ([]models.Statement, error) {
+	statements := []models.Statement{}
+	for rows.Next() {
+		stmt, err := scanStatement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		statements = append(statements, stmt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return statements, nil
+}
+
+// NewStatement сохраняет заявки и записывает событие создания для каждой из них.
+// Ключ идемпотентности key сохраняется в той же транзакции вместе с id созданных заявок.
+// Если ключ уже обработан, заявки не создаются повторно: возвращается сохраненная запись и false.
+func (s *Storage) NewStatement(ctx context.Context, key models.IdempotencyKey, statements []models.Statement, actorID int64) (models.IdempotencyKey, bool, error) {
 	const op = "storage.postgres.NewStatement"
 
-	ctx := context.Background()
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%s: begin tx: %w", op, err)
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: begin tx: %w", op, err)
 	}
 	defer tx.Rollback()
+
+	// Конкурентная вставка того же ключа ждет коммита этой транзакции и получает сохраненный результат.
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash, result, completed_at, expires_at)
+		VALUES ($1, $2, '{}', NOW(), $3)
+		ON CONFLICT (key) DO NOTHING`,
+		key.Key,
+		key.RequestHash,
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: insert idempotency key: %w", op, err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if inserted == 0 {
+		tx.Rollback()
+		stored, err := s.getIdempotencyKey(ctx, key.Key)
+		if err != nil {
+			return models.IdempotencyKey{}, false, fmt.Errorf("%s: %w", op, err)
+		}
+		return stored, false, nil
+	}
+
+	result := models.SubmissionResult{StatementIDs: make([]int, 0, len(statements))}
 	for _, stmt := range statements {
-		if stmt.CreatedAt == "" {
-			stmt.CreatedAt = time.Now().Format("2006-01-02")
+		if stmt.CreatedAt.IsZero() {
+			stmt.CreatedAt = time.Now()
 		}
+		// Заявка, сразу созданная решенной (например, при импорте), решена в момент сохранения.
+		var resolvedAt *time.Time
+		if stmt.Status == models.StatusResolved {
+			now := time.Now()
+			resolvedAt = &now
+		}
+		suggestedCategory, suggestedSubcategory, suggestionSource, suggestionConfidence := suggestionColumns(stmt.Suggestion)
 		// Вставляем запись
-		_, err = tx.ExecContext(ctx, `
+		created, err := scanStatement(tx.QueryRowContext(ctx, `
 		INSERT INTO statements (
 		source, district, category, subcategory,
-		created_at, status, admin_status, description
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		ON CONFLICT DO NOTHING`,
+		created_at, resolved_at, status, admin_status, description,
+		suggested_category, suggested_subcategory, suggestion_confidence, suggestion_source
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING `+statementColumns,
 			stmt.Source,
 			stmt.District,
 			stmt.Category,
 			stmt.Subcategory,
 			stmt.CreatedAt,
+			resolvedAt,
 			stmt.Status,
 			stmt.AdminStatus,
 			stmt.Description,
-		)
+			suggestedCategory,
+			suggestedSubcategory,
+			suggestionConfidence,
+			suggestionSource,
+		))
+		if err != nil {
+			return models.IdempotencyKey{}, false, fmt.Errorf("%s: insert statement: %w", op, err)
+		}
 
+		err = insertEvent(ctx, tx, models.StatementEvent{
+			StatementID: created.StatementUID,
+			Type:        models.EventCreated,
+			ActorID:     actorID,
+			Changes:     models.DiffStatements(nil, &created),
+		}, created)
 		if err != nil {
-			return fmt.Errorf("%s: insert statement: %w", op, err)
+			return models.IdempotencyKey{}, false, fmt.Errorf("%s: %w", op, err)
 		}
+		result.StatementIDs = append(result.StatementIDs, created.StatementUID)
+	}
+
+	key.Result, err = json.Marshal(result)
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: marshal result: %w", op, err)
+	}
+	if err := tx.QueryRowContext(ctx, `
+		UPDATE idempotency_keys
+		SET result = $2
+		WHERE key = $1
+		RETURNING created_at, completed_at`,
+		key.Key,
+		key.Result,
+	).Scan(&key.CreatedAt, &key.CompletedAt); err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: save result: %w", op, err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("%s: commit: %w", op, err)
+		return models.IdempotencyKey{}, false, fmt.Errorf("%s: commit: %w", op, err)
 	}
 
-	return nil
+	return key, true, nil
 }
 
 // GetStatement получает одно обращение по id
 func (s *Storage) GetStatement(id int) (models.Statement, error) {
 	const op = "storage.postgres.GetStatement"
 
-	var stmt models.Statement
-
 	ctx := context.Background()
-	err := s.db.QueryRowContext(ctx, `
-		SELECT 
-		id,
-		source,
-		district,
-		category,
-		subcategory,
-		created_at,
-		status,
-		admin_status,
-		description
+	stmt, err := scanStatement(s.db.QueryRowContext(ctx, `
+		SELECT `+statementColumns+`
 		FROM statements
 		WHERE id = $1`,
 		id,
-	).Scan(
-		&stmt.StatementUID,
-		&stmt.Source,
-		&stmt.District,
-		&stmt.Category,
-		&stmt.Subcategory,
-		&stmt.CreatedAt,
-		&stmt.Status,
-		&stmt.AdminStatus,
-		&stmt.Description,
-	)
+	))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.Statement{}, fmt.Errorf("%s: statement not found (id=%d)", op, id)
+			return models.Statement{}, fmt.Errorf("%s: statement (id=%d): %w", op, id, repository.ErrNotFound)
 		}
 		return models.Statement{}, fmt.Errorf("%s: query: %w", op, err)
 	}
@@ -145,33 +264,52 @@ func (s *Storage) GetStatement(id int) (models.Statement, error) {
 	return stmt, nil
 }
 
-func (s *Storage) DeleteStatement(id int) error {
+// DeleteStatement удаляет заявку, сохраняя ее последние значения в истории
+func (s *Storage) DeleteStatement(id int, actorID int64) error {
 	const op = "storage.postgres.DeleteStatement"
 
 	ctx := context.Background()
-	res, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	current, err := getStatementForUpdate(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
 		DELETE 
 		FROM statements
 		WHERE id = $1`,
 		id,
-	)
-	if err != nil {
+	); err != nil {
 		return fmt.Errorf("%s: exec: %w", op, err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	err = insertEvent(ctx, tx, models.StatementEvent{
+		StatementID: id,
+		Type:        models.EventDeleted,
+		ActorID:     actorID,
+		Changes:     models.DiffStatements(&current, nil),
+	}, current)
 	if err != nil {
-		return fmt.Errorf("%s: rows affected: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("%s: statement not found (id=%d)", op, id)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
 	}
 
 	return nil
 }
 
-func (s *Storage) UpdateStatement(ctx context.Context, statements []models.Statement) error {
+// UpdateStatement сохраняет новые значения заявок и записывает изменившиеся поля в историю.
+// Изменение только admin_status записывается как решение модератора.
+// Подсказка категории удаляется, если категория выбрана вручную или совпала с подсказкой (см. models.SuggestionSettled).
+func (s *Storage) UpdateStatement(ctx context.Context, statements []models.Statement, actorID int64) error {
 	const op = "storage.postgres.UpdateStatement"
 
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -188,11 +326,29 @@ func (s *Storage) UpdateStatement(ctx context.Context, statements []models.State
 			subcategory = $4,
 			status      = $5,
 			admin_status = $6,
-			description = $7
-		WHERE id = $8`
+			description = $7,
+			updated_at  = NOW(),
+			suggested_category    = CASE WHEN $9 THEN NULL ELSE suggested_category END,
+			suggested_subcategory = CASE WHEN $9 THEN NULL ELSE suggested_subcategory END,
+			suggestion_confidence = CASE WHEN $9 THEN NULL ELSE suggestion_confidence END,
+			suggestion_source     = CASE WHEN $9 THEN NULL ELSE suggestion_source END
+		WHERE id = $8
+		RETURNING ` + statementColumns
 	for _, stmt := range statements {
-		// Вставляем запись
-		_, err := s.db.ExecContext(ctx, query,
+		current, err := getStatementForUpdate(ctx, tx, stmt.StatementUID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Подсказка категории снимается, когда модератор выбрал категорию сам или принял подсказку.
+		settled := models.SuggestionSettled(&current, &stmt)
+		changes := models.DiffStatements(&current, &stmt)
+		if len(changes) == 0 && !settled {
+			continue
+		}
+
+		// Обновляем запись
+		updated, err := scanStatement(tx.QueryRowContext(ctx, query,
 			stmt.Source,
 			stmt.District,
 			stmt.Category,
@@ -201,10 +357,28 @@ func (s *Storage) UpdateStatement(ctx context.Context, statements []models.State
 			stmt.AdminStatus,
 			stmt.Description,
 			stmt.StatementUID,
-		)
+			settled,
+		))
+		if err != nil {
+			return fmt.Errorf("%s: update statement: %w", op, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
 
+		eventType := models.EventUpdated
+		if _, ok := changes["admin_status"]; ok && len(changes) == 1 {
+			eventType = models.EventModerated
+		}
+
+		err = insertEvent(ctx, tx, models.StatementEvent{
+			StatementID: stmt.StatementUID,
+			Type:        eventType,
+			ActorID:     actorID,
+			Changes:     changes,
+		}, updated)
 		if err != nil {
-			return fmt.Errorf("%s: insert statement: %w", op, err)
+			return fmt.Errorf("%s: %w", op, err)
 		}
 	}
 
@@ -215,145 +389,33 @@ func (s *Storage) UpdateStatement(ctx context.Context, statements []models.State
 	return nil
 }
 
-func (s *Storage) GetRecomendatonsContext(ctx context.Context) ([]models.Statement, error) {
+// GetRecomendatonsContext возвращает до 100 последних заявок, подходящих под фильтр.
+func (s *Storage) GetRecomendatonsContext(ctx context.Context, filter models.StatementFilter) ([]models.Statement, error) {
 	const op = "storage.postgres.GetRecomendatonsContext"
 
+	where, args := filterConditions(filter, nil)
+
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT 
-		id,
-		source,
-		district,
-		category,
-		subcategory,
-		created_at,
-		status,
-		description
+		SELECT `+statementColumns+`
 		FROM statements
-		WHERE admin_status = false
+		WHERE `+where+`
 		ORDER BY created_at DESC
-		LIMIT 100
-		`,
+		LIMIT 100`,
+		args...,
 	)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return []models.Statement{}, fmt.Errorf("%s: statements not found", op)
-		}
 		return []models.Statement{}, fmt.Errorf("%s: query: %w", op, err)
 	}
+	defer rows.Close()
 
-	var statements []models.Statement
-	for rows.Next() {
-		var stmt models.Statement
-		err := rows.Scan(&stmt.StatementUID,
-			&stmt.Source,
-			&stmt.District,
-			&stmt.Category,
-			&stmt.Subcategory,
-			&stmt.CreatedAt,
-			&stmt.Status,
-			&stmt.Description,
-		)
-		if err != nil {
-			return []models.Statement{}, fmt.Errorf("%s: statements not found", op)
-		}
-		statements = append(statements, stmt)
-	}
-
-	return statements, nil
-}
-
-func (s *Storage) GetAllNewStatements(ctx context.Context) ([]models.Statement, error) {
-	const op = "storage.postgres.GetStatement"
-
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT 
-		id,
-		source,
-		district,
-		category,
-		subcategory,
-		created_at,
-		status,
-		description
-		FROM statements
-		WHERE admin_status = true`,
-	)
+	statements, err := scanStatements(rows)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return []models.Statement{}, fmt.Errorf("%s: statements not found", op)
-		}
-		return []models.Statement{}, fmt.Errorf("%s: query: %w", op, err)
-	}
-
-	var statements []models.Statement
-	for rows.Next() {
-		var stmt models.Statement
-		err := rows.Scan(&stmt.StatementUID,
-			&stmt.Source,
-			&stmt.District,
-			&stmt.Category,
-			&stmt.Subcategory,
-			&stmt.CreatedAt,
-			&stmt.Status,
-			&stmt.Description,
-		)
-		if err != nil {
-			return []models.Statement{}, fmt.Errorf("%s: statements not found", op)
-		}
-		statements = append(statements, stmt)
+		return []models.Statement{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return statements, nil
 }
 
-func (s *Storage) GetCategoriesAnalitic(ctx context.Context, district string) (map[string]int, error) {
-	const op = "storage.postgres.GetStatement"
-
-	var query string
-
-	if district == "1" {
-		query = `
-			SELECT 
-			category, COUNT(category)
-			FROM statements
-			WHERE admin_status = false
-				AND district != $1	
-			GROUP BY category
-			`
-	} else {
-		query = `
-			SELECT 
-			category, COUNT(category)
-			FROM statements
-			WHERE admin_status = false
-				AND district = $1
-			GROUP BY category
-			`
-	}
-
-	rows, err := s.db.QueryContext(ctx, query, district)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return map[string]int{}, fmt.Errorf("%s: statements not found", op)
-		}
-		return map[string]int{}, fmt.Errorf("%s: query: %w", op, err)
-	}
-
-	analitic := make(map[string]int)
-	for rows.Next() {
-		key, value := "", 0
-
-		err := rows.Scan(&key, &value)
-		if err != nil {
-			return map[string]int{}, fmt.Errorf("%s: statements not found", op)
-		}
-
-		analitic[key] = value
-	}
-
-	return analitic, nil
-}
-
 func (s *Storage) GetDistrictAnalitic(ctx context.Context) (map[string]int, error) {
 	const op = "storage.postgres.GetDistrictAnalitic"
 
@@ -387,38 +449,6 @@ func (s *Storage) GetDistrictAnalitic(ctx context.Context) (map[string]int, erro
 	return analitic, nil
 }
 
-func (s *Storage) GetPeriodAnalitic(ctx context.Context) (map[string]int, error) {
-	const op = "storage.postgres.GetPeriodAnalitic"
-
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT 
-		created_at, COUNT(created_at)
-		FROM statements
-		WHERE admin_status = false
-		GROUP BY created_at
-		`,
-	)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return map[string]int{}, fmt.Errorf("%s: statements not found", op)
-		}
-		return map[string]int{}, fmt.Errorf("%s: query: %w", op, err)
-	}
-
-	analitic := make(map[string]int)
-	for rows.Next() {
-		key, value := "", 0
-
-		err := rows.Scan(&key, &value)
-		if err != nil {
-			return map[string]int{}, fmt.Errorf("%s: statements not found", op)
-		}
-		analitic[key] = value
-	}
-
-	return analitic, nil
-}
-
 // Close closes the underlying database connection.
 // Should be called on application shutdown.
 func (s *Storage) Close() error {
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"hack/internal/models"
+	"html"
+	"strings"
+)
+
+// snippetStart and snippetStop wrap matched words in ts_headline output. They are private use characters,
+// removed from the description before highlighting, so only the highlight produces them.
+const (
+	snippetStart = "\uE000"
+	snippetStop  = "\uE001"
+)
+
+// snippetOptions configures ts_headline: matched words are wrapped in the sentinels, replaced by <mark> after escaping.
+const snippetOptions = "StartSel=\"" + snippetStart + "\", StopSel=\"" + snippetStop + "\", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// SearchStatements ищет заявки по тексту с русской морфологией.
+// Результаты отсортированы по релевантности, description возвращается с подсветкой совпадений.
+func (s *Storage) SearchStatements(ctx context.Context, q models.SearchQuery) ([]models.SearchHit, int, error) {
+	const op = "storage.postgres.SearchStatements"
+
+	args := []any{q.Text}
+	where, args := filterConditions(q.Filter, args)
+	where = "search_vector @@ websearch_to_tsquery('russian', $1) AND " + where
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM statements
+		WHERE `+where,
+		args...,
+	).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: count: %w", op, err)
+	}
+
+	args = append(args, snippetStart+snippetStop, snippetOptions, q.Limit, q.Offset)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+statementColumns+`,
+		ts_rank_cd(search_vector, websearch_to_tsquery('russian', $1)) AS rank,
+		ts_headline('russian', translate(description, $`+fmt.Sprint(len(args)-3)+`, ''),
+			websearch_to_tsquery('russian', $1), $`+fmt.Sprint(len(args)-2)+`)
+		FROM statements
+		WHERE `+where+`
+		ORDER BY rank DESC, id DESC
+		LIMIT $`+fmt.Sprint(len(args)-1)+` OFFSET $`+fmt.Sprint(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	hits := []models.SearchHit{}
+	for rows.Next() {
+		var hit models.SearchHit
+		stmt, err := scanStatement(rows, &hit.Rank, &hit.Snippet)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		hit.Statement = stmt
+		hit.Snippet = escapeSnippet(hit.Snippet)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return hits, total, nil
+}
+
+// snippetMarks turns the highlight sentinels into tags after escaping.
+var snippetMarks = strings.NewReplacer(snippetStart, "<mark>", snippetStop, "</mark>")
+
+// escapeSnippet HTML-escapes the citizen's text, including any tags typed in it,
+// and marks the highlighted words, so the snippet can be rendered as HTML safely.
+func escapeSnippet(snippet string) string {
+	return snippetMarks.Replace(html.EscapeString(snippet))
+}
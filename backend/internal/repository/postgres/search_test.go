@@ -0,0 +1,34 @@
+package postgres
+
+import "testing"
+
+func Test_escapeSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{
+			name:    "highlight",
+			snippet: "переполненные " + snippetStart + "контейнеры" + snippetStop + " во дворе",
+			want:    "переполненные <mark>контейнеры</mark> во дворе",
+		},
+		{
+			name:    "html in description",
+			snippet: `<script>alert("x")</script> ` + snippetStart + "мусор" + snippetStop,
+			want:    `&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>мусор</mark>`,
+		},
+		{
+			name:    "mark typed by the citizen",
+			snippet: "<mark>срочно</mark> " + snippetStart + "мусор" + snippetStop,
+			want:    "&lt;mark&gt;срочно&lt;/mark&gt; <mark>мусор</mark>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeSnippet(tt.snippet); got != tt.want {
+				t.Errorf("escapeSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
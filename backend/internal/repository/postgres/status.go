@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hack/internal/models"
+	"hack/internal/repository"
+)
+
+// ChangeStatus sets the new status and records the transition in one transaction.
+// Resolving sets resolved_at, reopening clears it.
+// The update only applies when the statement still has transition.From,
+// otherwise repository.ErrConflict is returned.
+func (s *Storage) ChangeStatus(ctx context.Context, transition models.StatusTransition) error {
+	const op = "storage.postgres.ChangeStatus"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	updated, err := scanStatement(tx.QueryRowContext(ctx, `
+		UPDATE statements
+		SET
+			status      = $1,
+			updated_at  = NOW(),
+			resolved_at = CASE
+				WHEN $1 = $4 THEN NOW()
+				WHEN $1 = $5 THEN NULL
+				ELSE resolved_at
+			END
+		WHERE id = $2 AND status = $3
+		RETURNING `+statementColumns,
+		transition.To,
+		transition.StatementID,
+		transition.From,
+		models.StatusResolved,
+		models.StatusReopened,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: statement (id=%d) is no longer %q: %w",
+			op, transition.StatementID, transition.From, repository.ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: update status: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO status_transitions (
+		statement_id, from_status, to_status, actor_id, reason
+		) VALUES ($1, $2, $3, $4, $5)`,
+		transition.StatementID,
+		transition.From,
+		transition.To,
+		sql.NullInt64{Int64: transition.ActorID, Valid: transition.ActorID != 0},
+		transition.Reason,
+	); err != nil {
+		return fmt.Errorf("%s: insert transition: %w", op, err)
+	}
+
+	err = insertEvent(ctx, tx, models.StatementEvent{
+		StatementID: transition.StatementID,
+		Type:        models.EventStatusChanged,
+		ActorID:     transition.ActorID,
+		Changes: map[string]models.FieldChange{
+			"status": {Old: transition.From, New: transition.To},
+		},
+		Comment: transition.Reason,
+	}, updated)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetStatusTransitions возвращает историю смены статусов заявки в хронологическом порядке
+func (s *Storage) GetStatusTransitions(ctx context.Context, statementID int) ([]models.StatusTransition, error) {
+	const op = "storage.postgres.GetStatusTransitions"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, statement_id, from_status, to_status, actor_id, reason, created_at
+		FROM status_transitions
+		WHERE statement_id = $1
+		ORDER BY created_at, id`,
+		statementID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	transitions := []models.StatusTransition{}
+	for rows.Next() {
+		var (
+			t       models.StatusTransition
+			actorID sql.NullInt64
+		)
+		if err := rows.Scan(&t.ID, &t.StatementID, &t.From, &t.To, &actorID, &t.Reason, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		t.ActorID = actorID.Int64
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return transitions, nil
+}
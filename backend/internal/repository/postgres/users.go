@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hack/internal/models"
+	"hack/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the PostgreSQL error code for unique_violation.
+const uniqueViolation = "23505"
+
+// CreateUser inserts a new user and returns its id.
+func (s *Storage) CreateUser(ctx context.Context, user models.User) (int64, error) {
+	const op = "storage.postgres.CreateUser"
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO users (username, password_hash, role)
+		VALUES ($1, $2, $3)
+		RETURNING id`,
+		user.Username,
+		user.PasswordHash,
+		user.Role,
+	).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return 0, fmt.Errorf("%s: user %q: %w", op, user.Username, repository.ErrAlreadyExists)
+		}
+		return 0, fmt.Errorf("%s: insert user: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetUserByUsername получает пользователя по логину
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	const op = "storage.postgres.GetUserByUsername"
+
+	var user models.User
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, username, password_hash, role, created_at
+		FROM users
+		WHERE username = $1`,
+		username,
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%s: user %q: %w", op, username, repository.ErrNotFound)
+		}
+		return models.User{}, fmt.Errorf("%s: query: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// GetUser получает пользователя по id
+func (s *Storage) GetUser(ctx context.Context, id int64) (models.User, error) {
+	const op = "storage.postgres.GetUser"
+
+	var user models.User
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, username, password_hash, role, created_at
+		FROM users
+		WHERE id = $1`,
+		id,
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%s: user (id=%d): %w", op, id, repository.ErrNotFound)
+		}
+		return models.User{}, fmt.Errorf("%s: query: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// ListUsers возвращает всех пользователей, отсортированных по id
+func (s *Storage) ListUsers(ctx context.Context) ([]models.User, error) {
+	const op = "storage.postgres.ListUsers"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, username, password_hash, role, created_at
+		FROM users
+		ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan: %w", op, err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// UpdateUserRole меняет роль пользователя
+func (s *Storage) UpdateUserRole(ctx context.Context, id int64, role models.Role) error {
+	const op = "storage.postgres.UpdateUserRole"
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users
+		SET role = $1
+		WHERE id = $2`,
+		role,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: user (id=%d): %w", op, id, repository.ErrNotFound)
+	}
+
+	return nil
+}
+
+// CreateSession stores a new session. Expired sessions of the same user are removed.
+func (s *Storage) CreateSession(ctx context.Context, session models.Session) error {
+	const op = "storage.postgres.CreateSession"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM sessions
+		WHERE user_id = $1 AND expires_at <= NOW()`,
+		session.UserID,
+	); err != nil {
+		return fmt.Errorf("%s: delete expired: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO sessions (token_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)`,
+		session.TokenHash,
+		session.UserID,
+		session.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("%s: insert session: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetSession returns a non-expired session by its token hash.
+func (s *Storage) GetSession(ctx context.Context, tokenHash string) (models.Session, error) {
+	const op = "storage.postgres.GetSession"
+
+	var session models.Session
+	err := s.db.QueryRowContext(ctx, `
+		SELECT token_hash, user_id, created_at, expires_at
+		FROM sessions
+		WHERE token_hash = $1 AND expires_at > NOW()`,
+		tokenHash,
+	).Scan(&session.TokenHash, &session.UserID, &session.CreatedAt, &session.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Session{}, fmt.Errorf("%s: session: %w", op, repository.ErrNotFound)
+		}
+		return models.Session{}, fmt.Errorf("%s: query: %w", op, err)
+	}
+
+	return session, nil
+}
+
+// DeleteSession removes a session. Deleting a missing session is not an error.
+func (s *Storage) DeleteSession(ctx context.Context, tokenHash string) error {
+	const op = "storage.postgres.DeleteSession"
+
+	if _, err := s.db.ExecContext(ctx, `
+		DELETE FROM sessions
+		WHERE token_hash = $1`,
+		tokenHash,
+	); err != nil {
+		return fmt.Errorf("%s: exec: %w", op, err)
+	}
+
+	return nil
+}
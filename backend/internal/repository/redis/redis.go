@@ -1,5 +1,5 @@
-// Package redis provides Redis-based implementation of order storage.
-// It handles database connection and order CRUD operations.
+// Package redis provides Redis-based implementation of the cache.
+// It handles the connection and tagged cache entries.
 package redis
 
 import (
@@ -8,76 +8,146 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// Redis represents Redis repository for orders.
+// Redis represents Redis cache.
 type Redis struct {
 	Client *redis.Client
 }
 
-// MustLoad initializes Redis storage with database connection.
-func MustLoad(log *slog.Logger, host, port, password string, DB int) *Redis {
-	const op = "storage.redis.MustLoad"
-
+// New creates Redis storage without connecting: the client connects on first use
+// and reconnects by itself, so Redis may be down at startup.
+func New(host, port, password string, DB int) *Redis {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", host, port),
 		Password: password,
 		DB:       DB,
 	})
 
-	if err := client.Ping(context.Background()).Err(); err != nil {
-		client.Close()
-		log.Error("%s: apply migrations: %v", op, err)
+	return &Redis{Client: client}
+}
+
+// MustLoad initializes Redis storage with database connection.
+// If Redis is unavailable, os.Exit is executed.
+func MustLoad(log *slog.Logger, host, port, password string, DB int) *Redis {
+	const op = "storage.redis.MustLoad"
+
+	r := New(host, port, password, DB)
+	if err := r.Ping(context.Background()); err != nil {
+		r.Close()
+		log.Error("failed to connect to redis", slog.String("op", op), slog.Any("err", err))
 		os.Exit(1)
 	}
 
-	return &Redis{Client: client}
+	return r
 }
 
-// GetOrder retrieves an order by its orderUID from the database.
-func (r *Redis) GetStatement(ctx context.Context, statementUID int) ([]byte, error) {
-	const op = "storage.redis.GetStatement"
+// Ping checks the connection to Redis.
+func (r *Redis) Ping(ctx context.Context) error {
+	const op = "storage.redis.Ping"
 
-	key := strconv.Itoa(statementUID)
+	if err := r.Client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// tagPrefix namespaces the sets of keys tagged with a tag.
+const tagPrefix = "tag:"
+
+// invalidateTag deletes the keys of the tag set KEYS[1] and the set itself atomically,
+// so a key tagged meanwhile is not left out of the set. DEL takes the keys in chunks to stay within unpack limits.
+var invalidateTag = redis.NewScript(`
+local keys = redis.call('SMEMBERS', KEYS[1])
+for i = 1, #keys, 1000 do
+	redis.call('DEL', unpack(keys, i, math.min(i + 999, #keys)))
+end
+redis.call('DEL', KEYS[1])
+return #keys
+`)
+
+// setTagged stores ARGV[1] under KEYS[1] for ARGV[2] milliseconds, or without expiration when it is 0,
+// and adds KEYS[1] to the tag sets KEYS[2..]. The expiration of a tag set is only extended:
+// it becomes the longest TTL of its keys, and a key without expiration makes the set persistent.
+var setTagged = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local created = redis.call('SADD', KEYS[i], KEYS[1]) == 1 and redis.call('SCARD', KEYS[i]) == 1
+	if ttl > 0 then
+		local current = redis.call('PTTL', KEYS[i])
+		if created or (current >= 0 and current < ttl) then
+			redis.call('PEXPIRE', KEYS[i], ttl)
+		end
+	else
+		redis.call('PERSIST', KEYS[i])
+	end
+end
+return #KEYS - 1
+`)
+
+// Get returns the value stored under the key, or nil when there is none.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	const op = "storage.redis.Get"
 
 	data, err := r.Client.Get(ctx, key).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("%s: get failed: %w", op, err)
+		return nil, fmt.Errorf("%s: get %q: %w", op, key, err)
 	}
 
 	return data, nil
 }
 
-// SetOrder adds a new order to the Redis or returns an error.
-func (r *Redis) SetStatement(ctx context.Context, statementUID int, data []byte, ttl time.Duration) error {
-	const op = "storage.redis.SetStatement"
+// Set stores the value for ttl and adds the key to the set of every tag.
+// A tag set lives at least as long as its longest-lived key, so the sets of tags
+// that are never invalidated expire instead of growing forever.
+func (r *Redis) Set(ctx context.Context, key string, data []byte, ttl time.Duration, tags ...string) error {
+	const op = "storage.redis.Set"
 
-	key := strconv.Itoa(statementUID)
-
-	err := r.Client.Set(ctx, key, data, ttl).Err()
-	if err != nil {
-		return fmt.Errorf("%s: set failed: %w", op, err)
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, key)
+	for _, tag := range tags {
+		keys = append(keys, tagPrefix+tag)
+	}
+	if err := setTagged.Run(ctx, r.Client, keys, data, ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("%s: set %q: %w", op, key, err)
 	}
 
 	return nil
 }
 
-// DeleteOrder deletes the order from Redis or returns an error.
-func (r *Redis) DeleteStatement(ctx context.Context, statementUID int) error {
-	const op = "storage.redis.DeleteStatement"
+// Delete removes the keys.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	const op = "storage.redis.Delete"
 
-	key := strconv.Itoa(statementUID)
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := r.Client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("%s: del: %w", op, err)
+	}
 
-	err := r.Client.Del(ctx, key).Err()
-	if err != nil {
-		return fmt.Errorf("%s: del failed: %w", op, err)
+	return nil
+}
+
+// InvalidateTags removes every key set with any of the tags, and the tag sets themselves.
+func (r *Redis) InvalidateTags(ctx context.Context, tags ...string) error {
+	const op = "storage.redis.InvalidateTags"
+
+	for _, tag := range tags {
+		if err := invalidateTag.Run(ctx, r.Client, []string{tagPrefix + tag}).Err(); err != nil {
+			return fmt.Errorf("%s: tag %q: %w", op, tag, err)
+		}
 	}
 
 	return nil
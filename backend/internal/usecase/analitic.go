@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"hack/internal/models"
+)
+
+// maxPeriodPoints caps the length of a period series, e.g. about five years of days.
+const maxPeriodPoints = 2000
+
+// autoBuckets are tried in order when no bucket is requested: the first one giving a series
+// of at most maxPeriodPoints is used.
+var autoBuckets = []models.Bucket{models.BucketDay, models.BucketWeek, models.BucketMonth}
+
+// GetPeriodAnalitic returns the number of statements per bucket between filter.From and filter.To.
+// Buckets without statements are filled with zeros. Without From the series starts at the first
+// matching statement, without To it ends with the current bucket.
+// Without a bucket, days are used unless the series gets too long, then weeks or months.
+func (uc *StatementUseCase) GetPeriodAnalitic(ctx context.Context, filter models.StatementFilter, bucket models.Bucket) (models.PeriodSeries, error) {
+	const op = "usecase.GetPeriodAnalitic"
+
+	if bucket != "" && !bucket.Valid() {
+		return models.PeriodSeries{}, fmt.Errorf("%s: unknown bucket %q: %w", op, bucket, ErrInvalidQuery)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return models.PeriodSeries{}, fmt.Errorf("%s: from must be before to: %w", op, ErrInvalidQuery)
+	}
+
+	key := analyticsKey("period", struct {
+		Filter models.StatementFilter
+		Bucket models.Bucket
+	}{filter, bucket})
+	series, err := cached(ctx, uc.cache, key, analyticsTTL, func(ctx context.Context) (models.PeriodSeries, error) {
+		if bucket != "" {
+			points, err := uc.statementRepo.GetPeriodAnalitic(ctx, filter, bucket)
+			if err != nil {
+				return models.PeriodSeries{}, fmt.Errorf("statementRepo get period analitic: %w", err)
+			}
+			return fillPeriod(points, bucket, filter.From, filter.To, time.Now())
+		}
+
+		// Daily counts add up to any coarser bucket, so they are loaded once.
+		points, err := uc.statementRepo.GetPeriodAnalitic(ctx, filter, autoBuckets[0])
+		if err != nil {
+			return models.PeriodSeries{}, fmt.Errorf("statementRepo get period analitic: %w", err)
+		}
+		return fillAutoPeriod(points, filter.From, filter.To, time.Now())
+	}, tagStatements)
+	if err != nil {
+		return models.PeriodSeries{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return series, nil
+}
+
+// GetCategoriesAnalitic returns the number of statements per category matching the filter.
+func (uc *StatementUseCase) GetCategoriesAnalitic(ctx context.Context, filter models.StatementFilter) (map[string]int, error) {
+	const op = "usecase.GetCategoriesAnalitic"
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%s: from must be before to: %w", op, ErrInvalidQuery)
+	}
+
+	analitic, err := cached(ctx, uc.cache, analyticsKey("categories", filter), analyticsTTL, func(ctx context.Context) (map[string]int, error) {
+		return uc.statementRepo.GetCategoriesAnalitic(ctx, filter)
+	}, tagStatements)
+	if err != nil {
+		return nil, fmt.Errorf("%s: statementRepo get categories analitic: %w", op, err)
+	}
+
+	return analitic, nil
+}
+
+// GetCrossTabAnalitic returns the district × category matrix of statements matching the filter
+// with row and column totals and percentage shares.
+func (uc *StatementUseCase) GetCrossTabAnalitic(ctx context.Context, filter models.StatementFilter) (models.CrossTab, error) {
+	const op = "usecase.GetCrossTabAnalitic"
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return models.CrossTab{}, fmt.Errorf("%s: from must be before to: %w", op, ErrInvalidQuery)
+	}
+
+	tab, err := cached(ctx, uc.cache, analyticsKey("crosstab", filter), analyticsTTL, func(ctx context.Context) (models.CrossTab, error) {
+		cells, err := uc.statementRepo.GetCrossTabAnalitic(ctx, filter)
+		if err != nil {
+			return models.CrossTab{}, err
+		}
+		return buildCrossTab(cells), nil
+	}, tagStatements)
+	if err != nil {
+		return models.CrossTab{}, fmt.Errorf("%s: statementRepo get cross tab analitic: %w", op, err)
+	}
+
+	return tab, nil
+}
+
+// buildCrossTab turns sparse (district, category) counts into a full matrix.
+func buildCrossTab(cells []models.CrossTabCell) models.CrossTab {
+	rowTotals := make(map[string]int)
+	columnTotals := make(map[string]int)
+	counts := make(map[[2]string]int, len(cells))
+	total := 0
+	for _, c := range cells {
+		rowTotals[c.District] += c.Count
+		columnTotals[c.Category] += c.Count
+		counts[[2]string{c.District, c.Category}] += c.Count
+		total += c.Count
+	}
+
+	tab := models.CrossTab{
+		Districts:    sortedKeys(rowTotals),
+		Categories:   sortedKeys(columnTotals),
+		Rows:         []models.CrossTabRow{},
+		ColumnTotals: []models.CrossTabTotal{},
+		Total:        total,
+	}
+
+	for _, district := range tab.Districts {
+		row := models.CrossTabRow{
+			District: district,
+			Cells:    make(map[string]models.CrossTabValue, len(tab.Categories)),
+			Total:    rowTotals[district],
+			Share:    percent(rowTotals[district], total),
+		}
+		for _, category := range tab.Categories {
+			count := counts[[2]string{district, category}]
+			row.Cells[category] = models.CrossTabValue{
+				Count:       count,
+				RowShare:    percent(count, rowTotals[district]),
+				ColumnShare: percent(count, columnTotals[category]),
+				Share:       percent(count, total),
+			}
+		}
+		tab.Rows = append(tab.Rows, row)
+	}
+
+	for _, category := range tab.Categories {
+		tab.ColumnTotals = append(tab.ColumnTotals, models.CrossTabTotal{
+			Category: category,
+			Total:    columnTotals[category],
+			Share:    percent(columnTotals[category], total),
+		})
+	}
+
+	return tab
+}
+
+// backlogAgeBounds splits open statements by age: under a day, 1–3 days, 3–7 days,
+// 1–2 weeks, 2 weeks – 30 days and older.
+var backlogAgeBounds = []time.Duration{
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// GetResolutionAnalitic returns time-to-resolution, SLA compliance and backlog age
+// of statements matching the query, overall and broken down by q.GroupBy (district by default).
+func (uc *StatementUseCase) GetResolutionAnalitic(ctx context.Context, q models.ResolutionQuery) (models.ResolutionReport, error) {
+	const op = "usecase.GetResolutionAnalitic"
+
+	if q.GroupBy == "" {
+		q.GroupBy = models.GroupByDistrict
+	}
+	if !q.GroupBy.Valid() {
+		return models.ResolutionReport{}, fmt.Errorf("%s: unknown group %q: %w", op, q.GroupBy, ErrInvalidQuery)
+	}
+	if q.SLA <= 0 {
+		return models.ResolutionReport{}, fmt.Errorf("%s: sla must be positive: %w", op, ErrInvalidQuery)
+	}
+	if !q.Filter.From.IsZero() && !q.Filter.To.IsZero() && !q.Filter.From.Before(q.Filter.To) {
+		return models.ResolutionReport{}, fmt.Errorf("%s: from must be before to: %w", op, ErrInvalidQuery)
+	}
+
+	report, err := cached(ctx, uc.cache, analyticsKey("resolution", q), analyticsTTL, func(ctx context.Context) (models.ResolutionReport, error) {
+		total, groups, err := uc.statementRepo.GetResolutionAnalitic(ctx, q, backlogAgeBounds)
+		if err != nil {
+			return models.ResolutionReport{}, err
+		}
+
+		report := models.ResolutionReport{
+			SLAHours: q.SLA.Hours(),
+			GroupBy:  q.GroupBy,
+			Total:    finishResolutionStats(total, backlogAgeBounds),
+			Groups:   make([]models.ResolutionStats, 0, len(groups)),
+		}
+		for _, g := range groups {
+			report.Groups = append(report.Groups, finishResolutionStats(g, backlogAgeBounds))
+		}
+		return report, nil
+	}, tagStatements)
+	if err != nil {
+		return models.ResolutionReport{}, fmt.Errorf("%s: statementRepo get resolution analitic: %w", op, err)
+	}
+
+	return report, nil
+}
+
+// finishResolutionStats rounds durations, computes the SLA share and labels the backlog buckets.
+func finishResolutionStats(st models.ResolutionStats, bounds []time.Duration) models.ResolutionStats {
+	round := func(hours float64) float64 { return math.Round(hours*100) / 100 }
+
+	st.MedianHours = round(st.MedianHours)
+	st.P90Hours = round(st.P90Hours)
+	st.MeanHours = round(st.MeanHours)
+	st.SLAShare = percent(st.WithinSLA, st.Resolved)
+
+	backlog := make([]models.AgeBucket, len(bounds)+1)
+	for i := range backlog {
+		if i < len(st.Backlog) {
+			backlog[i].Count = st.Backlog[i].Count
+		}
+		var from time.Duration
+		if i > 0 {
+			from = bounds[i-1]
+		}
+		backlog[i].FromHours = from.Hours()
+		if i < len(bounds) {
+			to := bounds[i].Hours()
+			backlog[i].ToHours = &to
+			backlog[i].Label = fmt.Sprintf("%s–%s", ageLabel(from), ageLabel(bounds[i]))
+		} else {
+			backlog[i].Label = fmt.Sprintf("%s+", ageLabel(from))
+		}
+	}
+	st.Backlog = backlog
+
+	return st
+}
+
+// ageLabel formats whole days as "3d" and anything shorter as hours.
+func ageLabel(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%gh", d.Hours())
+}
+
+// percent returns part/whole in percent rounded to two decimals, zero for an empty whole.
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*10000/float64(whole)) / 100
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fillAutoPeriod fills daily points into the finest of autoBuckets that fits maxPeriodPoints.
+func fillAutoPeriod(points []models.PeriodPoint, from, to, now time.Time) (models.PeriodSeries, error) {
+	var err error
+	for _, bucket := range autoBuckets {
+		var series models.PeriodSeries
+		series, err = fillPeriod(points, bucket, from, to, now)
+		if err == nil {
+			return series, nil
+		}
+	}
+	return models.PeriodSeries{}, err
+}
+
+// fillPeriod turns sparse points into a continuous series over [from, to).
+// Zero from starts at the first point, zero to ends after the bucket containing now.
+func fillPeriod(points []models.PeriodPoint, bucket models.Bucket, from, to, now time.Time) (models.PeriodSeries, error) {
+	series := models.PeriodSeries{Bucket: bucket, From: from, To: to, Points: []models.PeriodPoint{}}
+
+	if from.IsZero() {
+		if len(points) == 0 {
+			return series, nil
+		}
+		from = points[0].Start
+	}
+	if to.IsZero() {
+		to = bucket.Next(bucket.Truncate(now))
+	}
+	series.From, series.To = from, to
+
+	counts := make(map[int64]int, len(points))
+	for _, p := range points {
+		counts[bucket.Truncate(p.Start).Unix()] += p.Count
+	}
+
+	for start := bucket.Truncate(from); start.Before(to); start = bucket.Next(start) {
+		if len(series.Points) == maxPeriodPoints {
+			return models.PeriodSeries{}, fmt.Errorf("series longer than %d %ss, narrow the range: %w", maxPeriodPoints, bucket, ErrInvalidQuery)
+		}
+		count := counts[start.Unix()]
+		series.Total += count
+		series.Points = append(series.Points, models.PeriodPoint{
+			Start: start,
+			Label: bucket.Label(start),
+			Count: count,
+		})
+	}
+
+	return series, nil
+}
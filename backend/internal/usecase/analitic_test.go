@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, models.CityLocation)
+}
+
+func counts(series models.PeriodSeries) []int {
+	var got []int
+	for _, p := range series.Points {
+		got = append(got, p.Count)
+	}
+	return got
+}
+
+func Test_fillPeriod(t *testing.T) {
+	points := []models.PeriodPoint{
+		{Start: day(2023, 12, 4), Count: 3},
+		{Start: day(2023, 12, 6), Count: 2},
+	}
+
+	tests := []struct {
+		name      string
+		bucket    models.Bucket
+		from, to  time.Time
+		want      []int
+		wantLabel string
+	}{
+		{
+			name: "days with gaps", bucket: models.BucketDay,
+			from: day(2023, 12, 3), to: day(2023, 12, 8),
+			want: []int{0, 3, 0, 2, 0}, wantLabel: "2023-12-03",
+		},
+		{
+			name: "from first point", bucket: models.BucketDay,
+			to:   day(2023, 12, 7),
+			want: []int{3, 0, 2}, wantLabel: "2023-12-04",
+		},
+		{
+			name: "weeks", bucket: models.BucketWeek,
+			from: day(2023, 11, 29), to: day(2023, 12, 12),
+			want: []int{0, 5, 0}, wantLabel: "2023-11-27",
+		},
+		{
+			name: "months", bucket: models.BucketMonth,
+			from: day(2023, 11, 15), to: day(2024, 2, 1),
+			want: []int{0, 5, 0}, wantLabel: "2023-11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pts := points
+			if tt.bucket != models.BucketDay {
+				// the repository already groups by the requested bucket
+				pts = []models.PeriodPoint{{Start: tt.bucket.Truncate(day(2023, 12, 4)), Count: 5}}
+			}
+			series, err := fillPeriod(pts, tt.bucket, tt.from, tt.to, time.Now())
+			if err != nil {
+				t.Fatalf("fillPeriod() error = %v", err)
+			}
+			if got := counts(series); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("counts = %v, want %v", got, tt.want)
+			}
+			if series.Points[0].Label != tt.wantLabel {
+				t.Errorf("first label = %q, want %q", series.Points[0].Label, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func Test_fillPeriod_Empty(t *testing.T) {
+	series, err := fillPeriod(nil, models.BucketDay, time.Time{}, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatalf("fillPeriod() error = %v", err)
+	}
+	if len(series.Points) != 0 {
+		t.Errorf("points = %v, want none", series.Points)
+	}
+}
+
+func Test_fillPeriod_TooLong(t *testing.T) {
+	_, err := fillPeriod(nil, models.BucketDay, day(2000, 1, 1), day(2024, 1, 1), time.Now())
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("fillPeriod() error = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func Test_fillAutoPeriod(t *testing.T) {
+	points := []models.PeriodPoint{
+		{Start: day(2000, 1, 3), Count: 1},
+		{Start: day(2000, 1, 4), Count: 2},
+		{Start: day(2020, 6, 1), Count: 3},
+	}
+
+	series, err := fillAutoPeriod(points[:2], time.Time{}, day(2000, 1, 6), time.Now())
+	if err != nil || series.Bucket != models.BucketDay || !reflect.DeepEqual(counts(series), []int{1, 2, 0}) {
+		t.Errorf("short range = %+v, %v, want days", series, err)
+	}
+
+	series, err = fillAutoPeriod(points, time.Time{}, day(2024, 1, 1), time.Now())
+	if err != nil {
+		t.Fatalf("fillAutoPeriod() error = %v", err)
+	}
+	if series.Bucket != models.BucketWeek || series.Total != 6 || series.Points[0].Count != 3 {
+		t.Errorf("long range = %s, total %d, first %+v, want weeks", series.Bucket, series.Total, series.Points[0])
+	}
+}
+
+func Test_buildCrossTab(t *testing.T) {
+	tab := buildCrossTab([]models.CrossTabCell{
+		{District: "Центральный", Category: "Мусор", Count: 3},
+		{District: "Центральный", Category: "Шум", Count: 1},
+		{District: "Адмиралтейский", Category: "Мусор", Count: 4},
+	})
+
+	if tab.Total != 8 {
+		t.Errorf("total = %d, want 8", tab.Total)
+	}
+	if want := []string{"Адмиралтейский", "Центральный"}; !reflect.DeepEqual(tab.Districts, want) {
+		t.Errorf("districts = %v, want %v", tab.Districts, want)
+	}
+	if want := []string{"Мусор", "Шум"}; !reflect.DeepEqual(tab.Categories, want) {
+		t.Errorf("categories = %v, want %v", tab.Categories, want)
+	}
+
+	admiralty := tab.Rows[0]
+	if got := admiralty.Cells["Шум"]; got != (models.CrossTabValue{}) {
+		t.Errorf("missing cell = %+v, want zero", got)
+	}
+	if admiralty.Total != 4 || admiralty.Share != 50 {
+		t.Errorf("row = %d (%v%%), want 4 (50%%)", admiralty.Total, admiralty.Share)
+	}
+
+	want := models.CrossTabValue{Count: 3, RowShare: 75, ColumnShare: 42.86, Share: 37.5}
+	if got := tab.Rows[1].Cells["Мусор"]; got != want {
+		t.Errorf("cell = %+v, want %+v", got, want)
+	}
+
+	wantTotals := []models.CrossTabTotal{
+		{Category: "Мусор", Total: 7, Share: 87.5},
+		{Category: "Шум", Total: 1, Share: 12.5},
+	}
+	if !reflect.DeepEqual(tab.ColumnTotals, wantTotals) {
+		t.Errorf("column totals = %+v, want %+v", tab.ColumnTotals, wantTotals)
+	}
+}
+
+func Test_finishResolutionStats(t *testing.T) {
+	bounds := []time.Duration{24 * time.Hour, 72 * time.Hour}
+	st := finishResolutionStats(models.ResolutionStats{
+		Resolved:    3,
+		WithinSLA:   2,
+		MedianHours: 10.123,
+		Backlog:     []models.AgeBucket{{Count: 1}, {Count: 0}, {Count: 4}},
+	}, bounds)
+
+	if st.SLAShare != 66.67 {
+		t.Errorf("sla share = %v, want 66.67", st.SLAShare)
+	}
+	if st.MedianHours != 10.12 {
+		t.Errorf("median = %v, want 10.12", st.MedianHours)
+	}
+
+	var labels []string
+	var got []int
+	for _, b := range st.Backlog {
+		labels = append(labels, b.Label)
+		got = append(got, b.Count)
+	}
+	if want := []string{"0d–1d", "1d–3d", "3d+"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+	if want := []int{1, 0, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+	if last := st.Backlog[2]; last.ToHours != nil || last.FromHours != 72 {
+		t.Errorf("last bucket = %+v, want from 72h without upper bound", last)
+	}
+}
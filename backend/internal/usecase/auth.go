@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"hack/internal/lib/validator"
+	"hack/internal/models"
+	"hack/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the username or password is wrong.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUnauthorized is returned when the session token is missing, unknown or expired.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInvalidUser is returned for a new user or a role failing validation.
+	ErrInvalidUser = errors.New("invalid user")
+)
+
+// UserRepository defines storage of users and their sessions.
+type UserRepository interface {
+	CreateUser(ctx context.Context, user models.User) (int64, error)
+	GetUser(ctx context.Context, id int64) (models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	ListUsers(ctx context.Context) ([]models.User, error)
+	UpdateUserRole(ctx context.Context, id int64, role models.Role) error
+
+	CreateSession(ctx context.Context, session models.Session) error
+	GetSession(ctx context.Context, tokenHash string) (models.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+}
+
+// AuthUseCase implements login, logout and session lookup for the admin panel.
+type AuthUseCase struct {
+	userRepo   UserRepository
+	sessionTTL time.Duration
+}
+
+// NewAuthUseCase creates a new instance of AuthUseCase.
+func NewAuthUseCase(userRepo UserRepository, sessionTTL time.Duration) *AuthUseCase {
+	return &AuthUseCase{
+		userRepo:   userRepo,
+		sessionTTL: sessionTTL,
+	}
+}
+
+// SessionTTL returns lifetime of the sessions created by Login.
+func (uc *AuthUseCase) SessionTTL() time.Duration {
+	return uc.sessionTTL
+}
+
+// Register creates a user with a bcrypt hash of the given password.
+func (uc *AuthUseCase) Register(ctx context.Context, newUser models.NewUser) (models.User, error) {
+	const op = "usecase.Register"
+
+	if err := validator.ValidateNewUser(&newUser); err != nil {
+		return models.User{}, fmt.Errorf("%s: validator: %w: %w", op, ErrInvalidUser, err)
+	}
+	if !newUser.Role.Valid() {
+		return models.User{}, fmt.Errorf("%s: unknown role %q: %w", op, newUser.Role, ErrInvalidUser)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: hash password: %w", op, err)
+	}
+
+	user := models.User{
+		Username:     newUser.Username,
+		Role:         newUser.Role,
+		PasswordHash: string(hash),
+	}
+
+	user.ID, err = uc.userRepo.CreateUser(ctx, user)
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: failed to save user: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// EnsureAdmin creates an administrator unless a user with the same name already exists,
+// in which case that user is promoted to administrator.
+// It is used to bootstrap the first account on startup.
+func (uc *AuthUseCase) EnsureAdmin(ctx context.Context, credentials models.Credentials) error {
+	const op = "usecase.EnsureAdmin"
+
+	_, err := uc.Register(ctx, models.NewUser{Credentials: credentials, Role: models.RoleAdmin})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := uc.userRepo.GetUserByUsername(ctx, credentials.Username)
+	if err != nil {
+		return fmt.Errorf("%s: userRepo get user: %w", op, err)
+	}
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+	if err := uc.userRepo.UpdateUserRole(ctx, user.ID, models.RoleAdmin); err != nil {
+		return fmt.Errorf("%s: userRepo update role: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListUsers returns all accounts for the admin API.
+func (uc *AuthUseCase) ListUsers(ctx context.Context) ([]models.User, error) {
+	const op = "usecase.ListUsers"
+
+	users, err := uc.userRepo.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: userRepo list users: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// SetUserRole assigns a new role to the user.
+// Administrators cannot demote themselves so the portal always keeps at least one of them.
+func (uc *AuthUseCase) SetUserRole(ctx context.Context, actor models.User, userID int64, role models.Role) error {
+	const op = "usecase.SetUserRole"
+
+	if !role.Valid() {
+		return fmt.Errorf("%s: unknown role %q: %w", op, role, ErrInvalidUser)
+	}
+	if actor.ID == userID && role != models.RoleAdmin {
+		return fmt.Errorf("%s: cannot change own role: %w", op, ErrForbidden)
+	}
+
+	if err := uc.userRepo.UpdateUserRole(ctx, userID, role); err != nil {
+		return fmt.Errorf("%s: userRepo update role: %w", op, err)
+	}
+
+	return nil
+}
+
+// Login checks the credentials and opens a new session.
+// It returns the raw session token which must be handed to the client.
+func (uc *AuthUseCase) Login(ctx context.Context, credentials models.Credentials) (string, models.User, error) {
+	const op = "usecase.Login"
+
+	if err := validator.ValidateCredentials(&credentials); err != nil {
+		return "", models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	user, err := uc.userRepo.GetUserByUsername(ctx, credentials.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		}
+		return "", models.User{}, fmt.Errorf("%s: userRepo get user: %w", op, err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
+		return "", models.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	token, err := newSessionToken()
+	if err != nil {
+		return "", models.User{}, fmt.Errorf("%s: generate token: %w", op, err)
+	}
+
+	session := models.Session{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(uc.sessionTTL),
+	}
+	if err := uc.userRepo.CreateSession(ctx, session); err != nil {
+		return "", models.User{}, fmt.Errorf("%s: failed to save session: %w", op, err)
+	}
+
+	return token, user, nil
+}
+
+// Logout closes the session identified by the raw token.
+func (uc *AuthUseCase) Logout(ctx context.Context, token string) error {
+	const op = "usecase.Logout"
+
+	if token == "" {
+		return nil
+	}
+
+	if err := uc.userRepo.DeleteSession(ctx, hashToken(token)); err != nil {
+		return fmt.Errorf("%s: failed to delete session: %w", op, err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the owner of a valid session.
+func (uc *AuthUseCase) Authenticate(ctx context.Context, token string) (models.User, error) {
+	const op = "usecase.Authenticate"
+
+	if token == "" {
+		return models.User{}, fmt.Errorf("%s: %w", op, ErrUnauthorized)
+	}
+
+	session, err := uc.userRepo.GetSession(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.User{}, fmt.Errorf("%s: %w", op, ErrUnauthorized)
+		}
+		return models.User{}, fmt.Errorf("%s: userRepo get session: %w", op, err)
+	}
+
+	user, err := uc.userRepo.GetUser(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.User{}, fmt.Errorf("%s: %w", op, ErrUnauthorized)
+		}
+		return models.User{}, fmt.Errorf("%s: userRepo get user: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// newSessionToken returns 32 random bytes encoded as hex.
+func newSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the value stored in the database for a raw session token,
+// so a leaked sessions table cannot be used to hijack sessions.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"hack/internal/lib/metrics"
+	"hack/internal/models"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache keys. Every kind of entry has its own namespace:
+//
+//	stmt:{id}                      a statement
+//	list:{queryhash}               a page of ListStatements
+//	analytics:{kind}:{filterhash}  a report of an analytics endpoint
+//	recs:{queryhash}               generated recommendations
+//
+// Lists and analytics are computed from many statements, so they are tagged with tagStatements
+// and dropped on every write. Recommendations are not: generating them is expensive, they expire instead.
+const (
+	statementTTL = 24 * time.Hour
+	listTTL      = time.Minute
+	analyticsTTL = 5 * time.Minute
+	recsTTL      = time.Hour
+)
+
+// tagStatements marks entries that change whenever any statement does.
+const tagStatements = "statements"
+
+// CacheRepository stores opaque values under string keys. Get returns nil data on a miss.
+// InvalidateTags deletes every entry set with any of the tags.
+type CacheRepository interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte, ttl time.Duration, tags ...string) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// statementCache keeps use case results in a CacheRepository under the key scheme above.
+// The cache is best effort: a failed read is a miss and a failed write is skipped.
+// A nil repository caches nothing.
+//
+// Concurrent misses of one key are coalesced: one caller loads the value and fills the cache,
+// the others wait for it. Without that an expired recommendations entry costs an LLM call per request.
+//
+// A write invalidating an entry while it is being loaded makes the loaded value stale:
+// the fill does not cache it and loads again, so neither the cache nor the waiting callers get it.
+type statementCache struct {
+	repo     CacheRepository
+	fills    *singleflight.Group
+	inflight *inflightFills
+}
+
+func newStatementCache(repo CacheRepository) statementCache {
+	return statementCache{repo: repo, fills: &singleflight.Group{}, inflight: &inflightFills{fills: make(map[*fill]struct{})}}
+}
+
+// maxFillAttempts limits the loads of one fill invalidated again and again by writes.
+// The value of the last attempt is returned without caching.
+const maxFillAttempts = 3
+
+// fill is a load of a cache key in progress. stale is set when the key or one of its tags
+// is invalidated after the load has started.
+type fill struct {
+	key   string
+	tags  []string
+	stale bool
+}
+
+// inflightFills tracks the running loads, so invalidation can reach those that have not cached their values yet.
+type inflightFills struct {
+	mu    sync.Mutex
+	fills map[*fill]struct{}
+}
+
+func (f *inflightFills) start(key string, tags []string) *fill {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fl := &fill{key: key, tags: tags}
+	f.fills[fl] = struct{}{}
+	return fl
+}
+
+func (f *inflightFills) done(fl *fill) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.fills, fl)
+}
+
+func (f *inflightFills) isStale(fl *fill) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fl.stale
+}
+
+// invalidate marks the loads of the keys and of entries with the tag stale and returns their keys.
+func (f *inflightFills) invalidate(keys []string, tag string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var stale []string
+	for fl := range f.fills {
+		if slices.Contains(keys, fl.key) || slices.Contains(fl.tags, tag) {
+			fl.stale = true
+			stale = append(stale, fl.key)
+		}
+	}
+	return stale
+}
+
+func statementKey(id int) string {
+	return "stmt:" + strconv.Itoa(id)
+}
+
+func listKey(query models.StatementQuery) string {
+	return "list:" + hashParams(query)
+}
+
+func analyticsKey(kind string, params any) string {
+	return "analytics:" + kind + ":" + hashParams(params)
+}
+
+func recsKey(q models.RecommendationQuery) string {
+	return "recs:" + hashParams(q)
+}
+
+// hashParams returns a short hash of the parameters encoded as JSON.
+func hashParams(params any) string {
+	data, _ := json.Marshal(params)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Statement returns the cached statement, or loads and caches it.
+func (c statementCache) Statement(ctx context.Context, id int, load func(ctx context.Context) (models.Statement, error)) (models.Statement, error) {
+	return cached(ctx, c, statementKey(id), statementTTL, load)
+}
+
+// Recommendations returns recommendations cached for the query, or generates and caches them.
+func (c statementCache) Recommendations(ctx context.Context, q models.RecommendationQuery, load func(ctx context.Context) ([]models.Recommendation, error)) ([]models.Recommendation, error) {
+	return cached(ctx, c, recsKey(q), recsTTL, load)
+}
+
+// InvalidateStatements drops the statements together with every list and report.
+// Without ids only lists and reports are dropped, e.g. after new statements are stored.
+// Loads of these entries in progress are marked stale before the entries are dropped,
+// and later callers do not join them.
+func (c statementCache) InvalidateStatements(ctx context.Context, ids ...int) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = statementKey(id)
+	}
+	for _, key := range c.inflight.invalidate(keys, tagStatements) {
+		c.fills.Forget(key)
+	}
+	for _, key := range keys {
+		c.fills.Forget(key)
+	}
+
+	if c.repo == nil {
+		return
+	}
+	if len(keys) > 0 {
+		c.repo.Delete(ctx, keys...)
+	}
+	c.repo.InvalidateTags(ctx, tagStatements)
+}
+
+// get decodes the entry into out and reports whether there was one. An undecodable entry is deleted.
+func (c statementCache) get(ctx context.Context, key string, out any) bool {
+	if c.repo == nil {
+		return false
+	}
+	data, err := c.repo.Get(ctx, key)
+	if err != nil || len(data) == 0 {
+		return false
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		c.repo.Delete(ctx, key)
+		return false
+	}
+	return true
+}
+
+func (c statementCache) set(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) {
+	if c.repo == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.repo.Set(ctx, key, data, ttl, tags...)
+}
+
+// cached returns the value cached under key, or loads it and caches it for ttl with the tags.
+// Load errors are returned as is and not cached.
+//
+// Only one load of a key runs at a time, concurrent callers wait for its result. The load gets
+// a context that is not canceled with the caller's, so a caller giving up does not fail the others.
+// Callers share the loaded value and must not modify it.
+//
+// A load made stale by InvalidateStatements is not cached and is repeated, up to maxFillAttempts loads.
+// The value cached just before the invalidation is deleted again, as the invalidation may have run before it was set.
+func cached[T any](ctx context.Context, c statementCache, key string, ttl time.Duration, load func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	var value T
+	if c.get(ctx, key, &value) {
+		return value, nil
+	}
+
+	leader := false
+	result := c.fills.DoChan(key, func() (any, error) {
+		leader = true
+		fillCtx := context.WithoutCancel(ctx)
+
+		// The value may have been cached while this caller was between get and DoChan.
+		var value T
+		if c.get(fillCtx, key, &value) {
+			return value, nil
+		}
+		for attempt := 1; ; attempt++ {
+			fl := c.inflight.start(key, tags)
+			value, err := load(fillCtx)
+			if err != nil {
+				c.inflight.done(fl)
+				return value, err
+			}
+			if !c.inflight.isStale(fl) {
+				c.set(fillCtx, key, value, ttl, tags...)
+				if !c.inflight.isStale(fl) {
+					c.inflight.done(fl)
+					return value, nil
+				}
+				if c.repo != nil {
+					c.repo.Delete(fillCtx, key)
+				}
+			}
+			c.inflight.done(fl)
+			if attempt == maxFillAttempts {
+				return value, nil
+			}
+		}
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case res := <-result:
+		if !leader {
+			metrics.CacheCoalescedWaits.WithLabelValues(metrics.CacheNamespace(key)).Inc()
+		}
+		if res.Err != nil {
+			return value, res.Err
+		}
+		return res.Val.(T), nil
+	}
+}
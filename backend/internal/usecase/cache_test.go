@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+// taggedCache keeps entries and tags in maps.
+type taggedCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	tags    map[string][]string
+}
+
+func newTaggedCache() *taggedCache {
+	return &taggedCache{entries: make(map[string][]byte), tags: make(map[string][]string)}
+}
+
+func (c *taggedCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key], nil
+}
+
+func (c *taggedCache) Set(_ context.Context, key string, data []byte, _ time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = data
+	for _, tag := range tags {
+		c.tags[tag] = append(c.tags[tag], key)
+	}
+	return nil
+}
+
+func (c *taggedCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *taggedCache) InvalidateTags(_ context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		for _, key := range c.tags[tag] {
+			delete(c.entries, key)
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+// countingRepo counts analytics queries and stores statements like submissionRepo.
+type countingRepo struct {
+	submissionRepo
+	categoryQueries int
+	statement       models.Statement
+}
+
+func (r *countingRepo) GetCategoriesAnalitic(context.Context, models.StatementFilter) (map[string]int, error) {
+	r.categoryQueries++
+	return map[string]int{"Мусор": len(r.saved)}, nil
+}
+
+func (r *countingRepo) GetStatement(int) (models.Statement, error) { return r.statement, nil }
+
+func (r *countingRepo) ChangeStatus(context.Context, models.StatusTransition) error { return nil }
+
+func TestStatementUseCase_CacheAside(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingRepo{statement: models.Statement{StatementUID: 5, Status: models.StatusNew}}
+	cache := newTaggedCache()
+	uc := NewStatementUseCase(repo, cache, &submissionBroker{}, nil, nil)
+
+	approved := false
+	filter := models.StatementFilter{AdminStatus: &approved}
+	categories := func() map[string]int {
+		t.Helper()
+		analitic, err := uc.GetCategoriesAnalitic(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return analitic
+	}
+
+	categories()
+	if got := categories(); repo.categoryQueries != 1 || got["Мусор"] != 0 {
+		t.Fatalf("categories = %v after %d queries, want a cached report", got, repo.categoryQueries)
+	}
+
+	trackingID, _, err := uc.CreateStatement(ctx, models.User{}, "", []models.Statement{testStatement()})
+	if err != nil || trackingID == "" {
+		t.Fatal(err)
+	}
+	if err := uc.StoreSubmission(ctx, uc.messageBroker.(*submissionBroker).value); err != nil {
+		t.Fatal(err)
+	}
+	if got := categories(); repo.categoryQueries != 2 || got["Мусор"] != 1 {
+		t.Errorf("categories = %v after %d queries, want the report recomputed after a new statement", got, repo.categoryQueries)
+	}
+
+	if _, err := uc.GetStatement(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.entries["stmt:5"]; !ok {
+		t.Fatalf("cache keys = %v, want stmt:5", cache.entries)
+	}
+
+	admin := models.User{ID: 1, Role: models.RoleAdmin}
+	if _, err := uc.ChangeStatus(ctx, admin, 5, models.StatusChange{Status: models.StatusInWork}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("cache keys = %v after a status change, want the statement and the report dropped", cache.entries)
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	approved := false
+	tests := []struct {
+		key    string
+		prefix string
+	}{
+		{statementKey(42), "stmt:42"},
+		{listKey(models.StatementQuery{Limit: 10}), "list:"},
+		{analyticsKey("crosstab", models.StatementFilter{AdminStatus: &approved}), "analytics:crosstab:"},
+		{recsKey(models.RecommendationQuery{Count: 5}), "recs:"},
+	}
+	for _, tt := range tests {
+		if !strings.HasPrefix(tt.key, tt.prefix) {
+			t.Errorf("key %q, want prefix %q", tt.key, tt.prefix)
+		}
+	}
+
+	if analyticsKey("categories", models.StatementFilter{Districts: []string{"Невский"}}) ==
+		analyticsKey("categories", models.StatementFilter{Districts: []string{"Центральный"}}) {
+		t.Error("reports of different filters share a key")
+	}
+	if recsKey(models.RecommendationQuery{Count: 2, Filter: models.StatementFilter{Categories: []string{"Мусор"}}}) ==
+		recsKey(models.RecommendationQuery{Count: 2}) {
+		t.Error("recommendations of different scopes share a key")
+	}
+}
+
+// blockingRepo returns the statement once release is closed and counts the queries.
+type blockingRepo struct {
+	StatementRepository
+	release chan struct{}
+	queries atomic.Int32
+}
+
+func (r *blockingRepo) GetStatement(id int) (models.Statement, error) {
+	r.queries.Add(1)
+	<-r.release
+	return models.Statement{StatementUID: id}, nil
+}
+
+func TestStatementUseCase_CoalescesFills(t *testing.T) {
+	repo := &blockingRepo{release: make(chan struct{})}
+	uc := NewStatementUseCase(repo, newTaggedCache(), nil, nil, nil)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statement, err := uc.GetStatement(context.Background(), 7)
+			if err == nil && statement.StatementUID != 7 {
+				err = fmt.Errorf("statement = %+v", statement)
+			}
+			errs <- err
+		}()
+	}
+
+	// Let the callers pile up behind the first query.
+	for repo.queries.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(repo.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := repo.queries.Load(); n != 1 {
+		t.Errorf("repository queried %d times, want 1", n)
+	}
+}
+
+// rewrittenRepo blocks the first GetStatement after it has read the description,
+// like a query that has read a row and is slow to return it.
+type rewrittenRepo struct {
+	StatementRepository
+	mu          sync.Mutex
+	description string
+	started     chan struct{}
+	release     chan struct{}
+	queries     atomic.Int32
+}
+
+func (r *rewrittenRepo) GetStatement(id int) (models.Statement, error) {
+	r.mu.Lock()
+	statement := models.Statement{StatementUID: id, Description: r.description}
+	r.mu.Unlock()
+	if r.queries.Add(1) == 1 {
+		close(r.started)
+		<-r.release
+	}
+	return statement, nil
+}
+
+func TestStatementUseCase_InvalidateDuringFill(t *testing.T) {
+	repo := &rewrittenRepo{description: "old", started: make(chan struct{}), release: make(chan struct{})}
+	uc := NewStatementUseCase(repo, newTaggedCache(), nil, nil, nil)
+	ctx := context.Background()
+
+	got := make(chan string, 2)
+	read := func() {
+		statement, err := uc.GetStatement(ctx, 7)
+		if err != nil {
+			t.Error(err)
+		}
+		got <- statement.Description
+	}
+	go read()
+	<-repo.started
+	go read()
+	// Let the second caller join the fill.
+	time.Sleep(20 * time.Millisecond)
+
+	// A write commits and invalidates the statement while the first load still holds the old row.
+	repo.mu.Lock()
+	repo.description = "new"
+	repo.mu.Unlock()
+	uc.cache.InvalidateStatements(ctx, 7)
+	close(repo.release)
+
+	for range 2 {
+		if description := <-got; description != "new" {
+			t.Errorf("read during the write = %q, want the new description", description)
+		}
+	}
+	statement, err := uc.GetStatement(ctx, 7)
+	if err != nil || statement.Description != "new" {
+		t.Errorf("cached statement = %+v, %v, want the new description", statement, err)
+	}
+	if n := repo.queries.Load(); n != 2 {
+		t.Errorf("repository queried %d times, want the stale load repeated once", n)
+	}
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hack/internal/lib/classifier"
+	"hack/internal/lib/llm"
+	"hack/internal/lib/logger/sl"
+	"hack/internal/lib/metrics"
+	"hack/internal/models"
+)
+
+// Classifier suggests the category and the subcategory of a statement from its description.
+// It returns nil without an error when it has no suggestion.
+type Classifier interface {
+	Classify(ctx context.Context, description string) (*models.CategorySuggestion, error)
+}
+
+// suggestTimeout limits the classification of one submission. Statements left when it runs out
+// are classified by the local model only, which does not wait for anything.
+const suggestTimeout = 5 * time.Second
+
+// suggestCategories sets the classifier's suggestion on the statements awaiting moderation
+// and drops suggestions sent by the client or published by older versions.
+// A failed classification leaves the statement without one.
+func (uc *StatementUseCase) suggestCategories(ctx context.Context, statements []models.Statement) {
+	for i := range statements {
+		statements[i].Suggestion = nil
+	}
+	if uc.classifier == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, suggestTimeout)
+	defer cancel()
+
+	for i := range statements {
+		if !statements[i].AdminStatus {
+			continue
+		}
+		suggestion, err := uc.classifier.Classify(ctx, statements[i].Description)
+		switch {
+		case err != nil:
+			metrics.CategorySuggestions.WithLabelValues("error").Inc()
+		case suggestion == nil:
+			metrics.CategorySuggestions.WithLabelValues("none").Inc()
+		default:
+			metrics.CategorySuggestions.WithLabelValues(suggestion.Source).Inc()
+			statements[i].Suggestion = suggestion
+		}
+	}
+}
+
+// AcceptSuggestion moves the statement to the suggested category and subcategory and drops the suggestion.
+// Accepting a suggestion is part of moderation, so it needs the moderate permission rather than edit.
+func (uc *StatementUseCase) AcceptSuggestion(ctx context.Context, actor models.User, statementUID int) (models.Statement, error) {
+	const op = "usecase.AcceptSuggestion"
+
+	if !Can(actor.Role, PermStatementModerate) {
+		return models.Statement{}, fmt.Errorf("%s: %s (id=%d): %w", op, PermStatementModerate, statementUID, ErrForbidden)
+	}
+
+	statement, err := uc.statementRepo.GetStatement(statementUID)
+	if err != nil {
+		return models.Statement{}, fmt.Errorf("%s: statementRepo get statement: %w", op, err)
+	}
+	if statement.Suggestion == nil {
+		return models.Statement{}, fmt.Errorf("%s: statement (id=%d): %w", op, statementUID, ErrNoSuggestion)
+	}
+
+	statement.Category = statement.Suggestion.Category
+	statement.Subcategory = statement.Suggestion.Subcategory
+	if err := uc.statementRepo.UpdateStatement(ctx, []models.Statement{statement}, actor.ID); err != nil {
+		return models.Statement{}, fmt.Errorf("%s: failed to save statement to repository: %w", op, err)
+	}
+	uc.cache.InvalidateStatements(ctx, statementUID)
+	statement.Suggestion = nil
+
+	return statement, nil
+}
+
+// ErrNoSuggestion is returned by AcceptSuggestion for a statement the classifier has not suggested anything for.
+var ErrNoSuggestion = errors.New("statement has no category suggestion")
+
+// labelSeparator joins a category and a subcategory into a label of the local model.
+const labelSeparator = "\x1f"
+
+// CategoryClassifier suggests categories with a TF-IDF model trained on approved statements
+// and, if it has an LLM client, asks the LLM first. The LLM may only choose a known
+// category and subcategory; when it fails or answers with an unknown one, the local model is used.
+type CategoryClassifier struct {
+	log          *slog.Logger
+	repo         StatementRepository
+	llmClient    LLMClient
+	interval     time.Duration
+	trainingSize int
+
+	mu    sync.RWMutex
+	model *classifier.Model
+}
+
+// NewCategoryClassifier creates a classifier trained on the latest trainingSize approved statements
+// every interval. llmClient may be nil to use the local model only.
+func NewCategoryClassifier(log *slog.Logger, repo StatementRepository, llmClient LLMClient, interval time.Duration, trainingSize int) *CategoryClassifier {
+	return &CategoryClassifier{
+		log:          log.With(slog.String("component", "category_classifier")),
+		repo:         repo,
+		llmClient:    llmClient,
+		interval:     interval,
+		trainingSize: trainingSize,
+	}
+}
+
+// Run trains the model at once and then every interval until the context is canceled.
+// A failed training keeps the previous model.
+func (c *CategoryClassifier) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Train(ctx); err != nil && ctx.Err() == nil {
+			c.log.Error("failed to train category classifier", sl.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Train rebuilds the model from the latest approved statements.
+func (c *CategoryClassifier) Train(ctx context.Context) error {
+	const op = "usecase.CategoryClassifier.Train"
+
+	approved := false
+	statements, _, err := c.repo.ListStatements(ctx, models.StatementQuery{
+		Filter: models.StatementFilter{AdminStatus: &approved},
+		SortBy: models.SortByID,
+		Desc:   true,
+		Limit:  c.trainingSize,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: statementRepo list statements: %w", op, err)
+	}
+
+	examples := make([]classifier.Example, len(statements))
+	for i, stmt := range statements {
+		examples[i] = classifier.Example{
+			Text:  stmt.Description,
+			Label: stmt.Category + labelSeparator + stmt.Subcategory,
+		}
+	}
+	model := classifier.Train(examples)
+
+	c.mu.Lock()
+	c.model = model
+	c.mu.Unlock()
+
+	c.log.Debug("category classifier trained", slog.Int("statements", len(statements)), slog.Int("labels", len(model.Labels())))
+
+	return nil
+}
+
+// Classify suggests a category for the description. It returns nil until the model is trained.
+func (c *CategoryClassifier) Classify(ctx context.Context, description string) (*models.CategorySuggestion, error) {
+	c.mu.RLock()
+	model := c.model
+	c.mu.RUnlock()
+
+	var llmErr error
+	if labels := model.Labels(); c.llmClient != nil && len(labels) > 0 && ctx.Err() == nil {
+		suggestion, err := c.classifyLLM(ctx, labels, description)
+		if err == nil {
+			return suggestion, nil
+		}
+		c.log.Warn("llm failed to classify statement, using local model", sl.Err(err))
+		llmErr = err
+	}
+
+	prediction, ok := model.Predict(description)
+	if !ok {
+		return nil, llmErr
+	}
+	category, subcategory, _ := strings.Cut(prediction.Label, labelSeparator)
+	return &models.CategorySuggestion{
+		Category:    category,
+		Subcategory: subcategory,
+		Confidence:  math.Round(prediction.Confidence*100) / 100,
+		Source:      models.SuggestionLocal,
+	}, nil
+}
+
+// classifyLLM asks the LLM to choose one of the labels for the description.
+func (c *CategoryClassifier) classifyLLM(ctx context.Context, labels []string, description string) (*models.CategorySuggestion, error) {
+	const op = "usecase.CategoryClassifier.classifyLLM"
+
+	var list strings.Builder
+	var categories, subcategories []string
+	for _, label := range labels {
+		category, subcategory, _ := strings.Cut(label, labelSeparator)
+		fmt.Fprintf(&list, "- %s / %s\n", category, subcategory)
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+		if !slices.Contains(subcategories, subcategory) {
+			subcategories = append(subcategories, subcategory)
+		}
+	}
+
+	answer, err := c.llmClient.Complete(ctx, llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleUser, Content: classifyPrompt(list.String(), description)},
+		},
+		Schema: classificationSchema(categories, subcategories),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var out models.CategorySuggestion
+	if err := json.Unmarshal([]byte(trimCodeFence(answer)), &out); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON: %w", op, err)
+	}
+	if !slices.Contains(labels, out.Category+labelSeparator+out.Subcategory) {
+		return nil, fmt.Errorf("%s: unknown category %q / %q", op, out.Category, out.Subcategory)
+	}
+	out.Confidence = math.Round(min(max(out.Confidence, 0), 1)*100) / 100
+	out.Source = models.SuggestionLLM
+
+	return &out, nil
+}
+
+func classifyPrompt(categories, description string) string {
+	return fmt.Sprintf(`Ты — модератор городского портала. Житель описал проблему, выбери для нее категорию и подкатегорию.
+
+Допустимые пары «категория / подкатегория»:
+%s
+Описание проблемы:
+%s
+
+Ответь JSON-объектом {"category": ..., "subcategory": ..., "confidence": ...}, где category и subcategory — пара
+точно из списка, а confidence — уверенность от 0 до 1. Не добавляй ничего, кроме JSON`, categories, description)
+}
+
+// classificationSchema is the JSON schema of the answer of classifyPrompt.
+func classificationSchema(categories, subcategories []string) *llm.Schema {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"category":    map[string]any{"type": "string", "enum": categories},
+			"subcategory": map[string]any{"type": "string", "enum": subcategories},
+			"confidence":  map[string]any{"type": "number"},
+		},
+		"required":             []string{"category", "subcategory", "confidence"},
+		"additionalProperties": false,
+	}
+
+	data, _ := json.Marshal(schema)
+	return &llm.Schema{Name: "classification", Schema: data}
+}
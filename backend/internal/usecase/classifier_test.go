@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"hack/internal/lib/llm"
+	"hack/internal/models"
+	"hack/internal/repository"
+)
+
+// classifierRepo serves approved statements for training and keeps statements by id.
+type classifierRepo struct {
+	StatementRepository
+	approved []models.Statement
+	queries  []models.StatementQuery
+	byID     map[int]models.Statement
+}
+
+func (r *classifierRepo) ListStatements(_ context.Context, query models.StatementQuery) ([]models.Statement, int, error) {
+	r.queries = append(r.queries, query)
+	return r.approved, len(r.approved), nil
+}
+
+func (r *classifierRepo) GetStatement(statementID int) (models.Statement, error) {
+	stmt, ok := r.byID[statementID]
+	if !ok {
+		return models.Statement{}, repository.ErrNotFound
+	}
+	return stmt, nil
+}
+
+func (r *classifierRepo) UpdateStatement(_ context.Context, statements []models.Statement, _ int64) error {
+	for _, stmt := range statements {
+		r.byID[stmt.StatementUID] = stmt
+	}
+	return nil
+}
+
+func newClassifierRepo() *classifierRepo {
+	roads := testStatement()
+	roads.Category, roads.Subcategory = "Дороги", "Ямы"
+	roads.Description = "Огромная яма на дороге, асфальт разрушен"
+	return &classifierRepo{approved: []models.Statement{testStatement(), roads}, byID: make(map[int]models.Statement)}
+}
+
+func trainedClassifier(t *testing.T, repo *classifierRepo, client LLMClient) *CategoryClassifier {
+	t.Helper()
+	c := NewCategoryClassifier(slog.New(slog.DiscardHandler), repo, client, 0, 100)
+	if err := c.Train(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCategoryClassifier_Local(t *testing.T) {
+	repo := newClassifierRepo()
+	c := NewCategoryClassifier(slog.New(slog.DiscardHandler), repo, nil, 0, 100)
+
+	if got, err := c.Classify(context.Background(), "Яма на дороге"); got != nil || err != nil {
+		t.Errorf("untrained Classify() = %+v, %v, want nothing", got, err)
+	}
+
+	if err := c.Train(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	q := repo.queries[0]
+	if q.Filter.AdminStatus == nil || *q.Filter.AdminStatus || q.Limit != 100 {
+		t.Errorf("training query = %+v, want the latest 100 approved statements", q)
+	}
+
+	got, err := c.Classify(context.Background(), "Яма на дороге")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Category != "Дороги" || got.Subcategory != "Ямы" || got.Source != models.SuggestionLocal {
+		t.Errorf("Classify() = %+v, want Дороги / Ямы from the local model", got)
+	}
+	if got.Confidence <= 0 || got.Confidence > 1 {
+		t.Errorf("confidence = %v, want (0, 1]", got.Confidence)
+	}
+}
+
+func TestCategoryClassifier_LLM(t *testing.T) {
+	client := llm.NewStub(`{"category": "Мусор", "subcategory": "Переполненные контейнеры", "confidence": 1.7}`)
+	c := trainedClassifier(t, newClassifierRepo(), client)
+
+	got, err := c.Classify(context.Background(), "Во дворе пахнет")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Category != "Мусор" || got.Source != models.SuggestionLLM || got.Confidence != 1 {
+		t.Errorf("Classify() = %+v, want Мусор from the llm with confidence 1", got)
+	}
+	if req := client.Requests()[0]; req.Schema == nil {
+		t.Error("request has no schema")
+	}
+}
+
+func TestCategoryClassifier_LLMFallback(t *testing.T) {
+	for name, answer := range map[string]string{
+		"not json":      "Мусор",
+		"unknown label": `{"category": "Мусор", "subcategory": "Ямы", "confidence": 0.9}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := trainedClassifier(t, newClassifierRepo(), llm.NewStub(answer))
+
+			got, err := c.Classify(context.Background(), "Яма на дороге")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || got.Category != "Дороги" || got.Source != models.SuggestionLocal {
+				t.Errorf("Classify() = %+v, want Дороги from the local model", got)
+			}
+			if _, err := c.Classify(context.Background(), "Сломалась скамейка"); err == nil {
+				t.Error("Classify(unknown words) error = nil, want the llm error")
+			}
+		})
+	}
+}
+
+// countingClassifier counts the classifications.
+type countingClassifier struct {
+	Classifier
+	calls int
+}
+
+func (c *countingClassifier) Classify(ctx context.Context, description string) (*models.CategorySuggestion, error) {
+	c.calls++
+	return c.Classifier.Classify(ctx, description)
+}
+
+func TestCreateStatement_NoSuggestion(t *testing.T) {
+	broker := &submissionBroker{}
+	classifier := &countingClassifier{Classifier: trainedClassifier(t, newClassifierRepo(), nil)}
+	uc := NewStatementUseCase(&submissionRepo{}, nil, broker, nil, classifier)
+
+	spoofed := testStatement()
+	spoofed.Description = "Яма на дороге"
+	spoofed.Suggestion = &models.CategorySuggestion{Category: "Дороги", Confidence: 1}
+	citizen := models.User{ID: 7, Role: models.RoleCitizen}
+	if _, _, err := uc.CreateStatement(context.Background(), citizen, "", []models.Statement{spoofed}); err != nil {
+		t.Fatal(err)
+	}
+
+	submission, err := decodeSubmission(broker.value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := submission.Statements[0].Suggestion; s != nil || classifier.calls != 0 {
+		t.Errorf("published suggestion = %+v after %d classifications, want none classified in the request", s, classifier.calls)
+	}
+}
+
+func TestStoreSubmission_Suggestion(t *testing.T) {
+	broker := &submissionBroker{}
+	repo := &submissionRepo{}
+	uc := NewStatementUseCase(repo, nil, broker, nil, trainedClassifier(t, newClassifierRepo(), nil))
+	ctx := context.Background()
+
+	pending := testStatement()
+	pending.Description = "Яма на дороге"
+	if _, _, err := uc.CreateStatement(ctx, models.User{ID: 7, Role: models.RoleCitizen}, "", []models.Statement{pending}); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.StoreSubmission(ctx, broker.value); err != nil {
+		t.Fatal(err)
+	}
+	if s := repo.saved[0].Suggestion; s == nil || s.Category != "Дороги" {
+		t.Errorf("suggestion = %+v, want Дороги", s)
+	}
+
+	if _, _, err := uc.CreateStatement(ctx, models.User{ID: 1, Role: models.RoleAdmin}, "", []models.Statement{pending}); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.StoreSubmission(ctx, broker.value); err != nil {
+		t.Fatal(err)
+	}
+	if s := repo.saved[1].Suggestion; s != nil {
+		t.Errorf("suggestion of an approved statement = %+v, want none", s)
+	}
+}
+
+func TestAcceptSuggestion(t *testing.T) {
+	repo := newClassifierRepo()
+	with := testStatement()
+	with.StatementUID = 1
+	with.Suggestion = &models.CategorySuggestion{Category: "Дороги", Subcategory: "Ямы", Confidence: 0.8, Source: models.SuggestionLocal}
+	without := testStatement()
+	without.StatementUID = 2
+	repo.byID[1], repo.byID[2] = with, without
+	uc := NewStatementUseCase(repo, nil, nil, nil, nil)
+
+	moderator := models.User{ID: 3, Role: models.RoleModerator}
+	if _, err := uc.AcceptSuggestion(context.Background(), models.User{ID: 7, Role: models.RoleCitizen}, 1); !errors.Is(err, ErrForbidden) {
+		t.Errorf("citizen: err = %v, want ErrForbidden", err)
+	}
+	if _, err := uc.AcceptSuggestion(context.Background(), moderator, 2); !errors.Is(err, ErrNoSuggestion) {
+		t.Errorf("without suggestion: err = %v, want ErrNoSuggestion", err)
+	}
+	if _, err := uc.AcceptSuggestion(context.Background(), moderator, 3); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("missing statement: err = %v, want ErrNotFound", err)
+	}
+
+	got, err := uc.AcceptSuggestion(context.Background(), moderator, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Category != "Дороги" || got.Subcategory != "Ямы" || got.Suggestion != nil || repo.byID[1].Category != "Дороги" {
+		t.Errorf("accepted statement = %+v, stored %+v", got, repo.byID[1])
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"hack/internal/lib/logger/sl"
+)
+
+// IdempotencyKeyRepository removes expired idempotency keys.
+type IdempotencyKeyRepository interface {
+	PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IdempotencyKeyPurger periodically removes idempotency keys older than idempotencyKeyTTL,
+// so the table does not grow with every request and Kafka message.
+type IdempotencyKeyPurger struct {
+	log      *slog.Logger
+	keyRepo  IdempotencyKeyRepository
+	interval time.Duration
+}
+
+// NewIdempotencyKeyPurger creates a purger removing expired keys every interval.
+func NewIdempotencyKeyPurger(log *slog.Logger, keyRepo IdempotencyKeyRepository, interval time.Duration) *IdempotencyKeyPurger {
+	return &IdempotencyKeyPurger{
+		log:      log.With(slog.String("component", "idempotency_purger")),
+		keyRepo:  keyRepo,
+		interval: interval,
+	}
+}
+
+// Run purges expired keys until the context is canceled. A failed purge is retried on the next tick.
+func (p *IdempotencyKeyPurger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+			p.log.Error("failed to purge idempotency keys", sl.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the keys expired by now.
+func (p *IdempotencyKeyPurger) Purge(ctx context.Context) error {
+	const op = "usecase.IdempotencyKeyPurger.Purge"
+
+	n, err := p.keyRepo.PurgeIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n > 0 {
+		p.log.Debug("idempotency keys purged", slog.Int64("keys", n))
+	}
+
+	return nil
+}
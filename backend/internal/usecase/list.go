@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"hack/internal/models"
+)
+
+const (
+	// DefaultPageSize is used when the query does not set a limit.
+	DefaultPageSize = 50
+	// MaxPageSize caps the limit of a single page.
+	MaxPageSize = 200
+)
+
+// ErrInvalidQuery is returned for unsupported sort fields, limits or malformed cursors.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ListStatements returns one page of statements matching the filter, ordered by query.SortBy.
+// The next page is requested by passing the returned NextCursor back as query.After.
+func (uc *StatementUseCase) ListStatements(ctx context.Context, query models.StatementQuery) (models.StatementPage, error) {
+	const op = "usecase.ListStatements"
+
+	if query.SortBy == "" {
+		query.SortBy = models.SortByCreatedAt
+	}
+	if !query.SortBy.Valid() {
+		return models.StatementPage{}, fmt.Errorf("%s: unknown sort field %q: %w", op, query.SortBy, ErrInvalidQuery)
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPageSize {
+		return models.StatementPage{}, fmt.Errorf("%s: limit must be between 1 and %d: %w", op, MaxPageSize, ErrInvalidQuery)
+	}
+	if query.After != nil && (query.After.SortBy != query.SortBy || query.After.Desc != query.Desc) {
+		return models.StatementPage{}, fmt.Errorf("%s: cursor was issued for another sort order: %w", op, ErrInvalidQuery)
+	}
+
+	page, err := cached(ctx, uc.cache, listKey(query), listTTL, func(ctx context.Context) (models.StatementPage, error) {
+		// One extra row tells whether there is a next page.
+		limit := query.Limit
+		query.Limit++
+
+		statements, total, err := uc.statementRepo.ListStatements(ctx, query)
+		if err != nil {
+			return models.StatementPage{}, err
+		}
+
+		page := models.StatementPage{Items: statements, Total: total}
+		if len(statements) > limit {
+			page.Items = statements[:limit]
+			last := page.Items[limit-1]
+			page.NextCursor = EncodeCursor(cursorAfter(last, query.SortBy, query.Desc))
+		}
+		return page, nil
+	}, tagStatements)
+	if err != nil {
+		return models.StatementPage{}, fmt.Errorf("%s: statementRepo list statements: %w", op, err)
+	}
+
+	return page, nil
+}
+
+// cursorAfter returns the cursor pointing right after the statement.
+func cursorAfter(s models.Statement, sortBy models.SortField, desc bool) models.PageCursor {
+	cursor := models.PageCursor{SortBy: sortBy, Desc: desc, ID: s.StatementUID}
+	switch sortBy {
+	case models.SortByCreatedAt:
+		cursor.SortValue = s.CreatedAt
+	case models.SortByUpdatedAt:
+		cursor.SortValue = s.UpdatedAt
+	}
+	return cursor
+}
+
+// EncodeCursor serializes the cursor into an opaque URL-safe string.
+func EncodeCursor(cursor models.PageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a string produced by EncodeCursor.
+func DecodeCursor(value string) (models.PageCursor, error) {
+	var cursor models.PageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, fmt.Errorf("malformed cursor: %w", ErrInvalidQuery)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || !cursor.SortBy.Valid() {
+		return cursor, fmt.Errorf("malformed cursor: %w", ErrInvalidQuery)
+	}
+
+	return cursor, nil
+}
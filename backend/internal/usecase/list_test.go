@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	statement := models.Statement{
+		StatementUID: 42,
+		CreatedAt:    time.Date(2023, 12, 9, 10, 30, 0, 0, time.UTC),
+		UpdatedAt:    time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name   string
+		sortBy models.SortField
+		desc   bool
+		want   time.Time
+	}{
+		{name: "created_at", sortBy: models.SortByCreatedAt, want: statement.CreatedAt},
+		{name: "updated_at desc", sortBy: models.SortByUpdatedAt, desc: true, want: statement.UpdatedAt},
+		{name: "id", sortBy: models.SortByID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := EncodeCursor(cursorAfter(statement, tt.sortBy, tt.desc))
+
+			got, err := DecodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if got.SortBy != tt.sortBy || got.Desc != tt.desc || got.ID != 42 || !got.SortValue.Equal(tt.want) {
+				t.Errorf("DecodeCursor() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, value := range []string{"not base64!", "e30", EncodeCursor(models.PageCursor{SortBy: "description"})} {
+		if _, err := DecodeCursor(value); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidQuery", value, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"hack/internal/lib/logger/sl"
+	"hack/internal/models"
+)
+
+// OutboxRepository hands out domain events written in the same transaction as statement changes
+// and submissions waiting for Kafka.
+type OutboxRepository interface {
+	PublishOutbox(ctx context.Context, topic string, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error)
+}
+
+// OutboxRelay publishes outbox messages of one topic to Kafka with at-least-once delivery:
+// a message is removed from the outbox only after the broker has accepted it.
+type OutboxRelay struct {
+	log           *slog.Logger
+	outboxRepo    OutboxRepository
+	topic         string
+	messageBroker MessageBroker
+	interval      time.Duration
+	batchSize     int
+	failing       bool
+}
+
+// NewOutboxRelay creates a relay polling the outbox topic every interval and publishing up to batchSize messages at a time.
+func NewOutboxRelay(log *slog.Logger, outboxRepo OutboxRepository, topic string, messageBroker MessageBroker, interval time.Duration, batchSize int) *OutboxRelay {
+	return &OutboxRelay{
+		log:           log.With(slog.String("component", "outbox_relay"), slog.String("topic", topic)),
+		outboxRepo:    outboxRepo,
+		topic:         topic,
+		messageBroker: messageBroker,
+		interval:      interval,
+		batchSize:     batchSize,
+	}
+}
+
+// Run publishes the outbox until the context is canceled.
+// The failed message is retried on the next tick. While Kafka is down only the first error is logged.
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		err := r.Flush(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil && !r.failing:
+			r.log.Error("failed to publish outbox, retrying every tick", sl.Err(err))
+			r.failing = true
+		case err == nil && r.failing:
+			r.log.Info("outbox is published again")
+			r.failing = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes batches until the outbox is empty or an error occurs.
+func (r *OutboxRelay) Flush(ctx context.Context) error {
+	const op = "usecase.OutboxRelay.Flush"
+
+	publish := func(ctx context.Context, msg models.OutboxMessage) error {
+		return r.messageBroker.Send(ctx, msg.Key, msg.Payload)
+	}
+
+	for {
+		n, err := r.outboxRepo.PublishOutbox(ctx, r.topic, r.batchSize, publish)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if n > 0 {
+			r.log.Debug("outbox published", slog.Int("messages", n))
+		}
+		if n < r.batchSize {
+			return nil
+		}
+	}
+}
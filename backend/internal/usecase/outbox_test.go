@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+// memoryOutbox keeps messages in a slice and removes them once published.
+type memoryOutbox struct {
+	messages []models.OutboxMessage
+}
+
+func (o *memoryOutbox) PublishOutbox(ctx context.Context, _ string, limit int, publish func(ctx context.Context, msg models.OutboxMessage) error) (int, error) {
+	n := 0
+	for n < limit && n < len(o.messages) {
+		if err := publish(ctx, o.messages[n]); err != nil {
+			o.messages = o.messages[n:]
+			return n, err
+		}
+		n++
+	}
+	o.messages = o.messages[n:]
+	return n, nil
+}
+
+// flakyBroker fails the send with the given number.
+type flakyBroker struct {
+	MessageBroker
+	sent   []string
+	failAt int
+}
+
+func (b *flakyBroker) Send(_ context.Context, key string, _ []byte) error {
+	if len(b.sent)+1 == b.failAt {
+		b.failAt = 0
+		return errors.New("broker unavailable")
+	}
+	b.sent = append(b.sent, key)
+	return nil
+}
+
+func TestOutboxRelay_Flush(t *testing.T) {
+	outbox := &memoryOutbox{}
+	for i := 1; i <= 5; i++ {
+		outbox.messages = append(outbox.messages, models.OutboxMessage{ID: int64(i), Key: strconv.Itoa(i)})
+	}
+	broker := &flakyBroker{failAt: 3}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	relay := NewOutboxRelay(log, outbox, models.OutboxEvents, broker, time.Second, 2)
+
+	if err := relay.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil, want the broker error")
+	}
+	if len(outbox.messages) != 3 {
+		t.Fatalf("outbox has %d messages after a failed send, want 3", len(outbox.messages))
+	}
+
+	if err := relay.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(outbox.messages) != 0 {
+		t.Errorf("outbox has %d messages, want none", len(outbox.messages))
+	}
+	want := []string{"1", "2", "3", "4", "5"}
+	if len(broker.sent) != len(want) {
+		t.Fatalf("sent %v, want %v", broker.sent, want)
+	}
+	for i := range want {
+		if broker.sent[i] != want[i] {
+			t.Errorf("sent %v, want %v", broker.sent, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"errors"
+
+	"hack/internal/models"
+)
+
+// ErrForbidden is returned when the actor's role lacks the required permission.
+var ErrForbidden = errors.New("forbidden")
+
+// Permission is an action guarded by the role model.
+type Permission string
+
+const (
+	PermStatementCreate   Permission = "statement:create"
+	PermStatementRead     Permission = "statement:read"
+	PermStatementModerate Permission = "statement:moderate"
+	PermStatementStatus   Permission = "statement:status"
+	PermStatementEdit     Permission = "statement:edit"
+	PermStatementDelete   Permission = "statement:delete"
+	PermUserManage        Permission = "user:manage"
+)
+
+// roleGuest is used for requests without a session.
+// Guests have the same rights as citizens so the public portal works without registration.
+const roleGuest models.Role = ""
+
+// permissions is the permission matrix: citizens create and read, moderators approve or reject,
+// executors change status, admins manage everything.
+var permissions = map[models.Role][]Permission{
+	roleGuest:          {PermStatementCreate, PermStatementRead},
+	models.RoleCitizen: {PermStatementCreate, PermStatementRead},
+	models.RoleModerator: {
+		PermStatementCreate, PermStatementRead,
+		PermStatementModerate, PermStatementDelete,
+	},
+	models.RoleExecutor: {
+		PermStatementCreate, PermStatementRead,
+		PermStatementStatus,
+	},
+	models.RoleAdmin: {
+		PermStatementCreate, PermStatementRead,
+		PermStatementModerate, PermStatementStatus, PermStatementEdit, PermStatementDelete,
+		PermUserManage,
+	},
+}
+
+// Can reports whether the role is granted the permission.
+func Can(role models.Role, perm Permission) bool {
+	for _, p := range permissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
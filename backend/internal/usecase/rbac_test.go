@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"hack/internal/models"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name string
+		role models.Role
+		perm Permission
+		want bool
+	}{
+		{name: "guest creates", role: roleGuest, perm: PermStatementCreate, want: true},
+		{name: "guest cannot moderate", role: roleGuest, perm: PermStatementModerate, want: false},
+		{name: "citizen reads", role: models.RoleCitizen, perm: PermStatementRead, want: true},
+		{name: "citizen cannot delete", role: models.RoleCitizen, perm: PermStatementDelete, want: false},
+		{name: "moderator moderates", role: models.RoleModerator, perm: PermStatementModerate, want: true},
+		{name: "moderator cannot change status", role: models.RoleModerator, perm: PermStatementStatus, want: false},
+		{name: "executor changes status", role: models.RoleExecutor, perm: PermStatementStatus, want: true},
+		{name: "executor cannot moderate", role: models.RoleExecutor, perm: PermStatementModerate, want: false},
+		{name: "admin manages users", role: models.RoleAdmin, perm: PermUserManage, want: true},
+		{name: "unknown role", role: "root", perm: PermStatementRead, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.role, tt.perm); got != tt.want {
+				t.Errorf("Can(%q, %q) = %v, want %v", tt.role, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_updatePermissions(t *testing.T) {
+	current := models.Statement{
+		StatementUID: 1,
+		Source:       "Городской портал",
+		District:     "Выборгский",
+		Category:     "Мусор",
+		Subcategory:  "Переполненные контейнеры",
+		Status:       "Новое",
+		AdminStatus:  true,
+		Description:  "Обращение по теме: переполненные контейнеры",
+	}
+
+	approved := current
+	approved.AdminStatus = false
+
+	edited := current
+	edited.District = "Петроградский"
+
+	editedAndApproved := edited
+	editedAndApproved.AdminStatus = false
+
+	tests := []struct {
+		name    string
+		updated models.Statement
+		want    []Permission
+	}{
+		{name: "unchanged", updated: current, want: nil},
+		{name: "moderation", updated: approved, want: []Permission{PermStatementModerate}},
+		{name: "edit", updated: edited, want: []Permission{PermStatementEdit}},
+		{name: "edit and moderation", updated: editedAndApproved, want: []Permission{PermStatementModerate, PermStatementEdit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updatePermissions(current, tt.updated); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("updatePermissions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
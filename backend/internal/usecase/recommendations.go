@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"hack/internal/lib/llm"
+	"hack/internal/lib/validator"
+	"hack/internal/models"
+)
+
+// maxRecommendations caps the number of recommendations generated at once.
+const maxRecommendations = 10
+
+var (
+	// ErrLLMNotConfigured is returned by GetRecomendations of a use case created without an LLM client.
+	ErrLLMNotConfigured = errors.New("llm client is not configured")
+	// ErrLLMUnavailable is returned when a request to the LLM provider fails.
+	ErrLLMUnavailable = errors.New("llm request failed")
+	// ErrInvalidRecommendations is returned when the model answers with invalid recommendations twice.
+	ErrInvalidRecommendations = errors.New("invalid recommendations")
+	// errEmptyCompletion is returned when there is nothing to generate recommendations from,
+	// so that the empty result is not cached.
+	errEmptyCompletion = errors.New("empty completion")
+)
+
+// GetRecomendations returns q.Count recommendations generated from the latest approved statements
+// matching q.Filter, together with their counts by district and category and, when q.SLA is set,
+// resolution statistics. Recommendations are cached per query.
+// The model answers in JSON, an answer failing validation is sent back once with a request to fix it.
+func (uc *StatementUseCase) GetRecomendations(ctx context.Context, q models.RecommendationQuery) ([]models.Recommendation, error) {
+	const op = "usecase.GetRecomendations"
+
+	if q.Count < 1 || q.Count > maxRecommendations {
+		return []models.Recommendation{}, fmt.Errorf("%s: count must be between 1 and %d: %w", op, maxRecommendations, ErrInvalidQuery)
+	}
+	if !q.Filter.From.IsZero() && !q.Filter.To.IsZero() && !q.Filter.From.Before(q.Filter.To) {
+		return []models.Recommendation{}, fmt.Errorf("%s: from must be before to: %w", op, ErrInvalidQuery)
+	}
+	approved := false
+	q.Filter.AdminStatus = &approved
+
+	result, err := uc.cache.Recommendations(ctx, q, func(ctx context.Context) ([]models.Recommendation, error) {
+		return uc.generateRecomendations(ctx, q)
+	})
+	if errors.Is(err, errEmptyCompletion) {
+		return []models.Recommendation{}, nil
+	}
+	if err != nil {
+		return []models.Recommendation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+func (uc *StatementUseCase) generateRecomendations(ctx context.Context, q models.RecommendationQuery) ([]models.Recommendation, error) {
+	if uc.llmClient == nil {
+		return nil, ErrLLMNotConfigured
+	}
+
+	statementsContext, err := uc.statementRepo.GetRecomendatonsContext(ctx, q.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("orderRepo get order: %w", err)
+	}
+	if len(statementsContext) == 0 {
+		return nil, errEmptyCompletion
+	}
+
+	tab, err := uc.GetCrossTabAnalitic(ctx, q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var resolution *models.ResolutionStats
+	if q.SLA > 0 {
+		report, err := uc.GetResolutionAnalitic(ctx, models.ResolutionQuery{Filter: q.Filter, SLA: q.SLA})
+		if err != nil {
+			return nil, err
+		}
+		resolution = &report.Total
+	}
+
+	scope := recommendationScope{districts: tab.Districts, categories: tab.Categories}
+	req := llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleUser, Content: GeneratePrompt(q.Count, statementsContext, recommendationSummary(q, tab, resolution))},
+		},
+		Schema: recommendationsSchema(q.Count, scope),
+	}
+
+	answer, err := uc.llmClient.Complete(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed get recomendations: %w: %w", ErrLLMUnavailable, err)
+	}
+	result, err := parseRecommendations(answer, q.Count, scope)
+	if err == nil {
+		return result, nil
+	}
+
+	req.Messages = append(req.Messages,
+		llm.Message{Role: llm.RoleAssistant, Content: answer},
+		llm.Message{Role: llm.RoleUser, Content: repairPrompt(err)},
+	)
+	answer, err = uc.llmClient.Complete(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed get recomendations: %w: %w", ErrLLMUnavailable, err)
+	}
+	result, err = parseRecommendations(answer, q.Count, scope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecommendations, err)
+	}
+
+	return result, nil
+}
+
+// recommendationScope is the districts and categories recommendations may refer to:
+// the ones of the statements matching the query.
+type recommendationScope struct {
+	districts  []string
+	categories []string
+}
+
+// recommendationSummary describes the scope of the query and the aggregated counts of its statements.
+func recommendationSummary(q models.RecommendationQuery, tab models.CrossTab, resolution *models.ResolutionStats) string {
+	var b strings.Builder
+
+	scope := func(name string, values []string) {
+		if len(values) == 0 {
+			fmt.Fprintf(&b, "%s: все\n", name)
+		} else {
+			fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(values, ", "))
+		}
+	}
+	scope("Районы", q.Filter.Districts)
+	scope("Категории", q.Filter.Categories)
+
+	fmt.Fprintf(&b, "Всего заявок: %d\n", tab.Total)
+	b.WriteString("По районам:\n")
+	for _, row := range tab.Rows {
+		fmt.Fprintf(&b, "- %s: %d (%g%%)\n", row.District, row.Total, row.Share)
+	}
+	b.WriteString("По категориям:\n")
+	for _, col := range tab.ColumnTotals {
+		fmt.Fprintf(&b, "- %s: %d (%g%%)\n", col.Category, col.Total, col.Share)
+	}
+
+	if resolution != nil {
+		fmt.Fprintf(&b, "Решено: %d, медиана времени решения %g ч, в срок %g%% (срок %g ч)\n",
+			resolution.Resolved, resolution.MedianHours, resolution.SLAShare, q.SLA.Hours())
+		fmt.Fprintf(&b, "Открыто: %d, из них просрочено: %d\n", resolution.Open, resolution.Overdue)
+	}
+
+	return b.String()
+}
+
+func GeneratePrompt(numRecommendations int, statements []models.Statement, summary string) string {
+	var contextBuilder strings.Builder
+	for _, stmt := range statements {
+		contextBuilder.WriteString(fmt.Sprintf(
+			"- Район: %s, Категория: %s/%s\n",
+			stmt.District,
+			stmt.Category,
+			stmt.Subcategory,
+		))
+	}
+
+	prompt := fmt.Sprintf(`Ты — городской аналитик. На основе предоставленных данных о проблемах города сформируй краткие практические рекомендации для жителей.
+
+Сводка по заявкам:
+%s
+Контекст (последние заявки от жителей):
+%s
+
+Инструкции:
+1. Проанализируй ВСЕ предоставленные заявки и выяви основные проблемы
+2. Сгенери ровно %d рекомендаций для жителей на основе текущей ситуации
+3. Каждая рекомендация должна быть:
+   - Практической и конкретной
+   - Не более 5 предложений
+   - Основана на реальных проблемах из контекста
+4. Формат вывода: JSON-объект {"recommendations": [...]}, где каждая рекомендация содержит:
+   - "title" — короткий заголовок
+   - "body" — текст рекомендации
+   - "districts" — затронутые районы, названия точно как в контексте
+   - "categories" — затронутые категории, названия точно как в контексте
+   - "priority" — "high", "medium" или "low"
+5. Не добавляй ничего, кроме JSON`, summary, contextBuilder.String(), numRecommendations)
+
+	return prompt
+}
+
+// repairPrompt asks the model to fix an answer that failed validation.
+func repairPrompt(err error) string {
+	return fmt.Sprintf(`Ответ не прошел проверку: %v.
+Исправь его и верни только JSON-объект {"recommendations": [...]} по схеме, без пояснений и разметки.`, err)
+}
+
+// recommendationsSchema is the JSON schema of an answer with count recommendations.
+// Districts and categories are limited to the ones of the scope.
+func recommendationsSchema(count int, scope recommendationScope) *llm.Schema {
+	stringEnum := func(values []string) map[string]any {
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string", "enum": values}}
+	}
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"recommendations": map[string]any{
+				"type":     "array",
+				"minItems": count,
+				"maxItems": count,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"title":      map[string]any{"type": "string"},
+						"body":       map[string]any{"type": "string"},
+						"districts":  stringEnum(scope.districts),
+						"categories": stringEnum(scope.categories),
+						"priority": map[string]any{
+							"type": "string",
+							"enum": []models.Priority{models.PriorityHigh, models.PriorityMedium, models.PriorityLow},
+						},
+					},
+					"required":             []string{"title", "body", "districts", "categories", "priority"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"recommendations"},
+		"additionalProperties": false,
+	}
+
+	data, _ := json.Marshal(schema)
+	return &llm.Schema{Name: "recommendations", Schema: data}
+}
+
+// parseRecommendations decodes and validates an answer. The error describes the problem
+// well enough to be sent back to the model.
+func parseRecommendations(answer string, count int, scope recommendationScope) ([]models.Recommendation, error) {
+	var out struct {
+		Recommendations []models.Recommendation `json:"recommendations"`
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(trimCodeFence(answer))))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(out.Recommendations) != count {
+		return nil, fmt.Errorf("got %d recommendations, want %d", len(out.Recommendations), count)
+	}
+
+	for i := range out.Recommendations {
+		rec := &out.Recommendations[i]
+		rec.Title = strings.TrimSpace(rec.Title)
+		rec.Body = strings.TrimSpace(rec.Body)
+		if err := validator.ValidateRecommendation(rec); err != nil {
+			return nil, fmt.Errorf("recommendation %d: %w", i+1, err)
+		}
+		for _, d := range rec.Districts {
+			if !slices.Contains(scope.districts, d) {
+				return nil, fmt.Errorf("recommendation %d: unknown district %q", i+1, d)
+			}
+		}
+		for _, c := range rec.Categories {
+			if !slices.Contains(scope.categories, c) {
+				return nil, fmt.Errorf("recommendation %d: unknown category %q", i+1, c)
+			}
+		}
+		if rec.Districts == nil {
+			rec.Districts = []string{}
+		}
+		if rec.Categories == nil {
+			rec.Categories = []string{}
+		}
+	}
+
+	return out.Recommendations, nil
+}
+
+// trimCodeFence strips the ```json fence models like to wrap JSON in.
+func trimCodeFence(answer string) string {
+	answer = strings.TrimSpace(answer)
+	if !strings.HasPrefix(answer, "```") {
+		return answer
+	}
+	answer = strings.TrimPrefix(answer, "```")
+	answer = strings.TrimPrefix(answer, "json")
+	answer = strings.TrimSuffix(answer, "```")
+	return strings.TrimSpace(answer)
+}
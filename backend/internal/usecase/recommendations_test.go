@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"hack/internal/lib/llm"
+	"hack/internal/models"
+)
+
+// recsRepo filters fixed statements by district and category for the context and the cross tab.
+type recsRepo struct {
+	StatementRepository
+	statements []models.Statement
+	filters    []models.StatementFilter
+}
+
+func (r *recsRepo) matching(filter models.StatementFilter) []models.Statement {
+	var out []models.Statement
+	for _, stmt := range r.statements {
+		if (len(filter.Districts) == 0 || slices.Contains(filter.Districts, stmt.District)) &&
+			(len(filter.Categories) == 0 || slices.Contains(filter.Categories, stmt.Category)) {
+			out = append(out, stmt)
+		}
+	}
+	return out
+}
+
+func (r *recsRepo) GetRecomendatonsContext(_ context.Context, filter models.StatementFilter) ([]models.Statement, error) {
+	r.filters = append(r.filters, filter)
+	return r.matching(filter), nil
+}
+
+func (r *recsRepo) GetCrossTabAnalitic(_ context.Context, filter models.StatementFilter) ([]models.CrossTabCell, error) {
+	var cells []models.CrossTabCell
+	for _, stmt := range r.matching(filter) {
+		cells = append(cells, models.CrossTabCell{District: stmt.District, Category: stmt.Category, Count: 1})
+	}
+	return cells, nil
+}
+
+func (r *recsRepo) GetResolutionAnalitic(context.Context, models.ResolutionQuery, []time.Duration) (models.ResolutionStats, []models.ResolutionStats, error) {
+	return models.ResolutionStats{Open: 1, Overdue: 1}, nil, nil
+}
+
+func recsQuery(count int) models.RecommendationQuery {
+	return models.RecommendationQuery{Count: count, SLA: 72 * time.Hour}
+}
+
+const validRecs = `{"recommendations": [
+	{"title": "Вывоз мусора", "body": "Сообщайте | о переполненных контейнерах", "districts": ["Центральный"], "categories": ["Мусор"], "priority": "high"},
+	{"title": "Дворы", "body": "Следите за чистотой", "districts": [], "categories": [], "priority": "low"}
+]}`
+
+func TestGetRecomendations(t *testing.T) {
+	repo := &recsRepo{statements: []models.Statement{testStatement()}}
+	client := llm.NewStub(validRecs)
+	uc := NewStatementUseCase(repo, newTaggedCache(), nil, client, nil)
+
+	for range 2 {
+		got, err := uc.GetRecomendations(context.Background(), recsQuery(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].Body != "Сообщайте | о переполненных контейнерах" || got[0].Priority != models.PriorityHigh {
+			t.Errorf("recommendations = %+v", got)
+		}
+	}
+
+	requests := client.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("llm called %d times, want 1: the second answer is cached", len(requests))
+	}
+	prompt := requests[0].Messages[0].Content
+	for _, want := range []string{"- Район: Центральный, Категория: Мусор", "- Центральный: 1 (100%)", "просрочено: 1"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt does not contain %q: %q", want, prompt)
+		}
+	}
+	if requests[0].Schema == nil {
+		t.Error("request has no schema")
+	}
+}
+
+func TestGetRecomendations_Scoped(t *testing.T) {
+	other := testStatement()
+	other.District, other.Category = "Невский", "Дороги"
+	repo := &recsRepo{statements: []models.Statement{testStatement(), other}}
+	client := llm.NewStub()
+	uc := NewStatementUseCase(repo, newTaggedCache(), nil, client, nil)
+
+	scoped := recsQuery(1)
+	scoped.Filter.Districts = []string{"Невский"}
+	for _, q := range []models.RecommendationQuery{scoped, recsQuery(1), scoped} {
+		got, err := uc.GetRecomendations(context.Background(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 {
+			t.Errorf("recommendations = %+v", got)
+		}
+	}
+
+	requests := client.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("llm called %d times, want 2: one per scope", len(requests))
+	}
+	prompt := requests[0].Messages[0].Content
+	if !strings.Contains(prompt, "Районы: Невский") || strings.Contains(prompt, "Центральный") {
+		t.Errorf("scoped prompt = %q, want only Невский", prompt)
+	}
+	if schema := string(requests[0].Schema.Schema); strings.Contains(schema, "Центральный") || !strings.Contains(schema, "Невский") {
+		t.Errorf("scoped schema = %s, want only Невский", schema)
+	}
+	for _, f := range repo.filters {
+		if f.AdminStatus == nil || *f.AdminStatus {
+			t.Errorf("filter %+v, want approved statements only", f)
+		}
+	}
+}
+
+func TestGetRecomendations_Repair(t *testing.T) {
+	repo := &recsRepo{statements: []models.Statement{testStatement()}}
+
+	t.Run("fixed", func(t *testing.T) {
+		client := llm.NewStub("Вывозите мусор | Чините дороги", validRecs)
+		uc := NewStatementUseCase(repo, nil, nil, client, nil)
+
+		got, err := uc.GetRecomendations(context.Background(), recsQuery(2))
+		if err != nil || len(got) != 2 {
+			t.Fatalf("recommendations = %+v, %v", got, err)
+		}
+		requests := client.Requests()
+		if len(requests) != 2 {
+			t.Fatalf("llm called %d times, want 2", len(requests))
+		}
+		repair := requests[1].Messages
+		if len(repair) != 3 || repair[1].Content != "Вывозите мусор | Чините дороги" || !strings.Contains(repair[2].Content, "invalid JSON") {
+			t.Errorf("repair messages = %+v", repair)
+		}
+	})
+
+	t.Run("still invalid", func(t *testing.T) {
+		client := llm.NewStub(`{"recommendations": []}`)
+		uc := NewStatementUseCase(repo, nil, nil, client, nil)
+
+		if _, err := uc.GetRecomendations(context.Background(), recsQuery(2)); !errors.Is(err, ErrInvalidRecommendations) {
+			t.Errorf("err = %v, want ErrInvalidRecommendations", err)
+		}
+		if n := len(client.Requests()); n != 2 {
+			t.Errorf("llm called %d times, want 2", n)
+		}
+	})
+}
+
+func TestGetRecomendations_Stub(t *testing.T) {
+	repo := &recsRepo{statements: []models.Statement{testStatement()}}
+	uc := NewStatementUseCase(repo, nil, nil, llm.NewStub(), nil)
+
+	got, err := uc.GetRecomendations(context.Background(), recsQuery(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Priority != models.PriorityHigh {
+		t.Errorf("recommendations = %+v, want 3 valid ones", got)
+	}
+}
+
+func TestGetRecomendations_Errors(t *testing.T) {
+	repo := &recsRepo{statements: []models.Statement{testStatement()}}
+
+	uc := NewStatementUseCase(repo, nil, nil, nil, nil)
+	if _, err := uc.GetRecomendations(context.Background(), recsQuery(2)); !errors.Is(err, ErrLLMNotConfigured) {
+		t.Errorf("err = %v, want ErrLLMNotConfigured", err)
+	}
+
+	uc = NewStatementUseCase(repo, nil, nil, llm.NewStub(), nil)
+	for _, count := range []int{0, maxRecommendations + 1} {
+		if _, err := uc.GetRecomendations(context.Background(), recsQuery(count)); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("count %d: err = %v, want ErrInvalidQuery", count, err)
+		}
+	}
+
+	uc = NewStatementUseCase(&recsRepo{}, nil, nil, llm.NewStub(), nil)
+	if got, err := uc.GetRecomendations(context.Background(), recsQuery(2)); err != nil || len(got) != 0 {
+		t.Errorf("without statements = %+v, %v, want none", got, err)
+	}
+
+	uc = NewStatementUseCase(repo, nil, nil, failingLLM{}, nil)
+	if _, err := uc.GetRecomendations(context.Background(), recsQuery(2)); !errors.Is(err, ErrLLMUnavailable) {
+		t.Errorf("llm down: err = %v, want ErrLLMUnavailable", err)
+	}
+}
+
+// failingLLM fails every completion like an unreachable provider.
+type failingLLM struct{}
+
+func (failingLLM) Complete(context.Context, llm.Request) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func Test_parseRecommendations(t *testing.T) {
+	scope := recommendationScope{districts: []string{"Центральный"}, categories: []string{"Мусор"}}
+	one := func(fields string) string {
+		return `{"recommendations": [{"title": "Заголовок", "body": "Текст", ` + fields + `}]}`
+	}
+
+	tests := []struct {
+		name    string
+		answer  string
+		wantErr string
+	}{
+		{name: "valid", answer: one(`"districts": ["Центральный"], "categories": ["Мусор"], "priority": "medium"`)},
+		{name: "code fence", answer: "```json\n" + one(`"districts": [], "categories": [], "priority": "low"`) + "\n```"},
+		{name: "not json", answer: "Вывозите мусор", wantErr: "invalid JSON"},
+		{name: "unknown field", answer: one(`"districts": [], "categories": [], "priority": "low", "score": 1`), wantErr: "invalid JSON"},
+		{name: "count", answer: `{"recommendations": []}`, wantErr: "got 0 recommendations, want 1"},
+		{name: "priority", answer: one(`"districts": [], "categories": [], "priority": "urgent"`), wantErr: "Priority"},
+		{name: "district", answer: one(`"districts": ["Невский"], "categories": [], "priority": "low"`), wantErr: `unknown district "Невский"`},
+		{name: "category", answer: one(`"districts": [], "categories": ["Дороги"], "priority": "low"`), wantErr: `unknown category "Дороги"`},
+		{name: "blank title", answer: `{"recommendations": [{"title": " ", "body": "Текст", "districts": [], "categories": [], "priority": "low"}]}`, wantErr: "Title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecommendations(tt.answer, 1, scope)
+			if tt.wantErr == "" {
+				if err != nil || len(got) != 1 || got[0].Districts == nil {
+					t.Errorf("parseRecommendations() = %+v, %v", got, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
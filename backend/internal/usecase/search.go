@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"hack/internal/models"
+)
+
+// maxSearchQueryLength limits the search text in characters.
+const maxSearchQueryLength = 200
+
+// SearchStatements runs a ranked full-text search over statement descriptions.
+func (uc *StatementUseCase) SearchStatements(ctx context.Context, query models.SearchQuery) (models.SearchResult, error) {
+	const op = "usecase.SearchStatements"
+
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return models.SearchResult{}, fmt.Errorf("%s: empty search text: %w", op, ErrInvalidQuery)
+	}
+	if utf8.RuneCountInString(query.Text) > maxSearchQueryLength {
+		return models.SearchResult{}, fmt.Errorf("%s: search text longer than %d characters: %w", op, maxSearchQueryLength, ErrInvalidQuery)
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPageSize {
+		return models.SearchResult{}, fmt.Errorf("%s: limit must be between 1 and %d: %w", op, MaxPageSize, ErrInvalidQuery)
+	}
+	if query.Offset < 0 {
+		return models.SearchResult{}, fmt.Errorf("%s: negative offset: %w", op, ErrInvalidQuery)
+	}
+
+	hits, total, err := uc.statementRepo.SearchStatements(ctx, query)
+	if err != nil {
+		return models.SearchResult{}, fmt.Errorf("%s: statementRepo search: %w", op, err)
+	}
+
+	return models.SearchResult{Items: hits, Total: total}, nil
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"hack/internal/lib/validator"
+	"hack/internal/models"
+)
+
+// ErrStatusChangeNotAllowed is returned when a generic update tries to change the status
+// instead of going through ChangeStatus.
+var ErrStatusChangeNotAllowed = errors.New("status can only be changed through a status transition")
+
+// ErrInvalidStatement is returned for statements and status changes failing validation.
+var ErrInvalidStatement = errors.New("invalid statement")
+
+// TransitionError is returned when the status workflow does not allow the requested transition.
+type TransitionError struct {
+	From   models.Status
+	To     models.Status
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal status transition %q -> %q: %s", e.From, e.To, e.Reason)
+}
+
+// transitions is the status workflow:
+//
+//	Новое → В работе → Решено → Переоткрыто → В работе → ...
+//	Новое, В работе, Переоткрыто → Отклонено → Переоткрыто
+var transitions = map[models.Status][]models.Status{
+	models.StatusNew:      {models.StatusInWork, models.StatusRejected},
+	models.StatusInWork:   {models.StatusResolved, models.StatusRejected},
+	models.StatusResolved: {models.StatusReopened},
+	models.StatusRejected: {models.StatusReopened},
+	models.StatusReopened: {models.StatusInWork, models.StatusRejected},
+}
+
+// reasonRequired lists target statuses that cannot be set without an explanation.
+var reasonRequired = map[models.Status]bool{
+	models.StatusRejected: true,
+	models.StatusReopened: true,
+}
+
+// CanTransition reports whether the workflow allows moving from one status to another.
+func CanTransition(from, to models.Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition validates the transition and returns a *TransitionError when it is illegal.
+func checkTransition(from, to models.Status, reason string) error {
+	if !to.Valid() {
+		return &TransitionError{From: from, To: to, Reason: "unknown status"}
+	}
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to, Reason: "not allowed by workflow"}
+	}
+	if reasonRequired[to] && strings.TrimSpace(reason) == "" {
+		return &TransitionError{From: from, To: to, Reason: "reason is required"}
+	}
+	return nil
+}
+
+// ChangeStatus moves the statement to a new status according to the workflow
+// and records who made the transition and why.
+func (uc *StatementUseCase) ChangeStatus(ctx context.Context, actor models.User, statementUID int, change models.StatusChange) (models.StatusTransition, error) {
+	const op = "usecase.ChangeStatus"
+
+	if err := validator.ValidateStatusChange(&change); err != nil {
+		return models.StatusTransition{}, fmt.Errorf("%s: validator: %w: %w", op, ErrInvalidStatement, err)
+	}
+	if !Can(actor.Role, PermStatementStatus) {
+		return models.StatusTransition{}, fmt.Errorf("%s: %w", op, ErrForbidden)
+	}
+
+	statement, err := uc.statementRepo.GetStatement(statementUID)
+	if err != nil {
+		return models.StatusTransition{}, fmt.Errorf("%s: statementRepo get statement: %w", op, err)
+	}
+
+	if err := checkTransition(statement.Status, change.Status, change.Reason); err != nil {
+		return models.StatusTransition{}, fmt.Errorf("%s: statement (id=%d): %w", op, statementUID, err)
+	}
+
+	transition := models.StatusTransition{
+		StatementID: statementUID,
+		From:        statement.Status,
+		To:          change.Status,
+		ActorID:     actor.ID,
+		Reason:      strings.TrimSpace(change.Reason),
+	}
+	if err := uc.statementRepo.ChangeStatus(ctx, transition); err != nil {
+		return models.StatusTransition{}, fmt.Errorf("%s: failed to save status: %w", op, err)
+	}
+	uc.cache.InvalidateStatements(ctx, statementUID)
+
+	return transition, nil
+}
+
+// GetStatusTransitions returns the recorded status changes of the statement.
+// Transitions of a deleted statement are kept; repository.ErrNotFound is returned
+// only for a statement that has neither transitions nor a row.
+func (uc *StatementUseCase) GetStatusTransitions(ctx context.Context, statementUID int) ([]models.StatusTransition, error) {
+	const op = "usecase.GetStatusTransitions"
+
+	transitions, err := uc.statementRepo.GetStatusTransitions(ctx, statementUID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: statementRepo get transitions: %w", op, err)
+	}
+	if len(transitions) == 0 {
+		if _, err := uc.statementRepo.GetStatement(statementUID); err != nil {
+			return nil, fmt.Errorf("%s: statementRepo get statement: %w", op, err)
+		}
+	}
+
+	return transitions, nil
+}
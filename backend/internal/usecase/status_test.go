@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"hack/internal/models"
+)
+
+func Test_checkTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    models.Status
+		to      models.Status
+		reason  string
+		wantErr bool
+	}{
+		{name: "take into work", from: models.StatusNew, to: models.StatusInWork},
+		{name: "resolve", from: models.StatusInWork, to: models.StatusResolved},
+		{name: "reopen resolved", from: models.StatusResolved, to: models.StatusReopened, reason: "мусор снова не вывезли"},
+		{name: "work on reopened", from: models.StatusReopened, to: models.StatusInWork},
+		{name: "reject new", from: models.StatusNew, to: models.StatusRejected, reason: "дубликат"},
+		{name: "reject without reason", from: models.StatusNew, to: models.StatusRejected, reason: "  ", wantErr: true},
+		{name: "skip work", from: models.StatusNew, to: models.StatusResolved, wantErr: true},
+		{name: "resolve rejected", from: models.StatusRejected, to: models.StatusResolved, wantErr: true},
+		{name: "same status", from: models.StatusInWork, to: models.StatusInWork, wantErr: true},
+		{name: "unknown status", from: models.StatusNew, to: "Закрыто", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTransition(tt.from, tt.to, tt.reason)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var transitionErr *TransitionError
+			if err != nil && !errors.As(err, &transitionErr) {
+				t.Errorf("checkTransition() error = %T, want *TransitionError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"hack/internal/lib/validator"
+	"hack/internal/models"
+)
+
+// ErrInvalidSubmission is returned by StoreSubmission for messages that can never be stored:
+// undecodable or failing validation. Retrying them is pointless.
+var ErrInvalidSubmission = errors.New("invalid submission")
+
+var (
+	// ErrInvalidIdempotencyKey is returned for an blank, too long or non-printable Idempotency-Key.
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrIdempotencyKeyReused is returned when a key is sent again with different statements.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+)
+
+const (
+	// maxIdempotencyKeyLen limits the Idempotency-Key header.
+	maxIdempotencyKeyLen = 255
+	// idempotencyKeyTTL is how long a processed key is remembered before IdempotencyKeyPurger removes it.
+	idempotencyKeyTTL = 7 * 24 * time.Hour
+)
+
+// CreateStatement validates the statements and publishes them to Kafka for asynchronous processing.
+// It does not wait for persistence — that's handled by the ingestion worker (see StoreSubmission).
+// Statements created by users without the moderate permission always go to the moderation queue,
+// and statements created by users without the status permission always start as Новое:
+// other statuses are only reached through ChangeStatus, which records the transition.
+// Only users with the edit permission may set created_at, e.g. to import old statements;
+// the others get the time the statements are accepted.
+// The statements are published as a statement.submitted envelope,
+// the returned tracking ID is its event ID and the key of the message.
+//
+// Suggestions sent by the client are dropped, statements going to moderation are classified
+// by the ingestion worker (see StoreSubmission), so the request does not wait for the classifier.
+//
+// If Kafka is unavailable, the message is put into the outbox instead and published by the relay
+// once Kafka is back, so submissions are still accepted.
+//
+// A non-empty idempotencyKey, scoped to the actor, makes retries safe: a replay with the same statements
+// returns the original tracking ID and replayed = true. A replay of a request whose publication failed
+// publishes it again under the original tracking ID, which the worker stores only once.
+// Guests share actor ID 0, so their keys would collide and are ignored.
+// Keys are remembered for idempotencyKeyTTL.
+func (uc *StatementUseCase) CreateStatement(ctx context.Context, actor models.User, idempotencyKey string, statements []models.Statement) (trackingID string, replayed bool, err error) {
+	const op = "usecase.CreateStatement"
+
+	if len(statements) == 0 {
+		return "", false, fmt.Errorf("%s: no statements: %w", op, ErrInvalidStatement)
+	}
+	if err := validateStatements(statements); err != nil {
+		return "", false, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range statements {
+		if !Can(actor.Role, PermStatementModerate) {
+			statements[i].AdminStatus = true
+		}
+		if !Can(actor.Role, PermStatementStatus) {
+			statements[i].Status = models.StatusNew
+		}
+		if !Can(actor.Role, PermStatementEdit) {
+			statements[i].CreatedAt = time.Time{}
+		}
+		statements[i].Suggestion = nil
+	}
+
+	trackingID, err = newTrackingID()
+	if err != nil {
+		return "", false, fmt.Errorf("%s: generate tracking id: %w", op, err)
+	}
+
+	var key models.IdempotencyKey
+	if idempotencyKey != "" && actor.ID != 0 {
+		var created bool
+		key, created, err = uc.reserveSubmission(ctx, actor, idempotencyKey, trackingID, statements)
+		if err != nil {
+			return "", false, fmt.Errorf("%s: %w", op, err)
+		}
+		if !created {
+			var result models.SubmissionResult
+			if err := json.Unmarshal(key.Result, &result); err != nil {
+				return "", false, fmt.Errorf("%s: decode idempotent result: %w", op, err)
+			}
+			trackingID, replayed = result.TrackingID, true
+			if key.CompletedAt != nil {
+				return trackingID, replayed, nil
+			}
+		}
+	}
+
+	submission := models.Submission{
+		TrackingID:  trackingID,
+		ActorID:     actor.ID,
+		SubmittedAt: time.Now(),
+		Statements:  statements,
+	}
+	var sender *models.Actor
+	if actor.ID != 0 {
+		sender = &models.Actor{ID: actor.ID}
+	}
+	envelope, err := models.WrapEvent(trackingID, models.EventStatementSubmitted, submission.SubmittedAt, sender, submission)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", op, err)
+	}
+	value, err := json.Marshal(envelope)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: json marshal err: %w", op, err)
+	}
+
+	if err := uc.messageBroker.Send(ctx, trackingID, value); err != nil {
+		outboxErr := uc.statementRepo.EnqueueOutbox(ctx, models.OutboxMessage{
+			Topic:   models.OutboxSubmissions,
+			Key:     trackingID,
+			Payload: value,
+		})
+		if outboxErr != nil {
+			return "", false, fmt.Errorf("%s: failed to publish statements: %w", op, errors.Join(err, outboxErr))
+		}
+	}
+
+	if key.Key != "" {
+		if err := uc.statementRepo.CompleteIdempotencyKey(ctx, key.Key); err != nil {
+			return "", false, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return trackingID, replayed, nil
+}
+
+// reserveSubmission stores the tracking ID under the actor's idempotency key,
+// or returns the key stored by an earlier request with the same statements.
+func (uc *StatementUseCase) reserveSubmission(ctx context.Context, actor models.User, idempotencyKey, trackingID string, statements []models.Statement) (models.IdempotencyKey, bool, error) {
+	if err := validateIdempotencyKey(idempotencyKey); err != nil {
+		return models.IdempotencyKey{}, false, err
+	}
+	hash, err := hashStatements(statements)
+	if err != nil {
+		return models.IdempotencyKey{}, false, err
+	}
+	result, err := json.Marshal(models.SubmissionResult{TrackingID: trackingID})
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("marshal idempotent result: %w", err)
+	}
+
+	key, created, err := uc.statementRepo.ReserveIdempotencyKey(ctx, models.IdempotencyKey{
+		Key:         "http:" + strconv.FormatInt(actor.ID, 10) + ":" + idempotencyKey,
+		RequestHash: hash,
+		Result:      result,
+		ExpiresAt:   time.Now().Add(idempotencyKeyTTL),
+	})
+	if err != nil {
+		return models.IdempotencyKey{}, false, fmt.Errorf("statementRepo reserve idempotency key: %w", err)
+	}
+	if key.RequestHash != hash {
+		return models.IdempotencyKey{}, false, ErrIdempotencyKeyReused
+	}
+	return key, created, nil
+}
+
+// StoreSubmission saves statements published by CreateStatement.
+// It has the signature of kafka.MessageHandler and is run by the ingestion worker.
+// Formats published by older versions are still accepted: a bare models.Submission
+// and a bare JSON array of statements, which is stored without an actor.
+//
+// Every message is stored once: the idempotency key is the tracking ID,
+// or the hash of the value for legacy messages without one, so identical legacy messages
+// are taken for redeliveries.
+//
+// Statements going to moderation get a category suggestion of the classifier, if there is one.
+func (uc *StatementUseCase) StoreSubmission(ctx context.Context, value []byte) error {
+	const op = "usecase.StoreSubmission"
+
+	submission, err := decodeSubmission(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, ErrInvalidSubmission, err)
+	}
+
+	if err := validateStatements(submission.Statements); err != nil {
+		return fmt.Errorf("%s: submission %q: %w: %w", op, submission.TrackingID, ErrInvalidSubmission, err)
+	}
+	for i := range submission.Statements {
+		if submission.Statements[i].CreatedAt.IsZero() {
+			submission.Statements[i].CreatedAt = submission.SubmittedAt
+		}
+	}
+
+	hash, err := hashStatements(submission.Statements)
+	if err != nil {
+		return fmt.Errorf("%s: submission %q: %w", op, submission.TrackingID, err)
+	}
+	// Suggestions may differ between deliveries, so they are added after the hash is taken.
+	uc.suggestCategories(ctx, submission.Statements)
+	key := models.IdempotencyKey{
+		Key:         "submission:" + submission.TrackingID,
+		RequestHash: hash,
+		ExpiresAt:   time.Now().Add(idempotencyKeyTTL),
+	}
+	if submission.TrackingID == "" {
+		sum := sha256.Sum256(value)
+		key.Key = "message:" + hex.EncodeToString(sum[:])
+	}
+
+	_, created, err := uc.statementRepo.NewStatement(ctx, key, submission.Statements, submission.ActorID)
+	if err != nil {
+		return fmt.Errorf("%s: submission %q: failed to save statement to repository: %w", op, submission.TrackingID, err)
+	}
+	if created {
+		uc.cache.InvalidateStatements(ctx)
+	}
+
+	return nil
+}
+
+// decodeSubmission reads a statement.submitted envelope or one of the legacy formats.
+func decodeSubmission(value []byte) (models.Submission, error) {
+	var submission models.Submission
+
+	if bytes.HasPrefix(bytes.TrimSpace(value), []byte("[")) {
+		if err := json.Unmarshal(value, &submission.Statements); err != nil {
+			return models.Submission{}, fmt.Errorf("json unmarshal err: %w", err)
+		}
+		return submission, nil
+	}
+
+	var probe struct {
+		Type models.EventName `json:"type"`
+	}
+	if err := json.Unmarshal(value, &probe); err != nil {
+		return models.Submission{}, fmt.Errorf("json unmarshal err: %w", err)
+	}
+	if probe.Type == "" {
+		if err := json.Unmarshal(value, &submission); err != nil {
+			return models.Submission{}, fmt.Errorf("json unmarshal err: %w", err)
+		}
+		return submission, nil
+	}
+
+	envelope, err := models.DecodeEnvelope(value)
+	if err != nil {
+		return models.Submission{}, err
+	}
+	if envelope.Type != models.EventStatementSubmitted {
+		return models.Submission{}, fmt.Errorf("event type %q: %w", envelope.Type, models.ErrUnsupportedEnvelope)
+	}
+	payload, err := envelope.DecodePayload()
+	if err != nil {
+		return models.Submission{}, err
+	}
+
+	return *payload.(*models.Submission), nil
+}
+
+func validateStatements(statements []models.Statement) error {
+	for i := range statements {
+		if err := validator.ValidateStatement(&statements[i]); err != nil {
+			return fmt.Errorf("validator: %w: %w", ErrInvalidStatement, err)
+		}
+		if !statements[i].Status.Valid() {
+			return fmt.Errorf("unknown status %q: %w", statements[i].Status, ErrInvalidStatement)
+		}
+	}
+	return nil
+}
+
+// validateIdempotencyKey accepts up to maxIdempotencyKeyLen printable characters.
+func validateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLen || strings.TrimSpace(key) == "" {
+		return ErrInvalidIdempotencyKey
+	}
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return ErrInvalidIdempotencyKey
+		}
+	}
+	return nil
+}
+
+// hashStatements returns the hex SHA-256 of the statements encoded as JSON.
+func hashStatements(statements []models.Statement) (string, error) {
+	data, err := json.Marshal(statements)
+	if err != nil {
+		return "", fmt.Errorf("hash statements: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newTrackingID returns 16 random bytes encoded as hex.
+func newTrackingID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,380 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"hack/internal/models"
+)
+
+// submissionRepo records statements passed to NewStatement and outbox messages,
+// and keeps idempotency keys in memory. EnqueueOutbox fails while outboxErr is set.
+type submissionRepo struct {
+	StatementRepository
+	saved     []models.Statement
+	actorID   int64
+	keys      map[string]models.IdempotencyKey
+	outbox    []models.OutboxMessage
+	outboxErr error
+}
+
+func (r *submissionRepo) NewStatement(_ context.Context, key models.IdempotencyKey, statements []models.Statement, actorID int64) (models.IdempotencyKey, bool, error) {
+	if stored, ok := r.keys[key.Key]; ok {
+		return stored, false, nil
+	}
+	now := time.Now()
+	key.CompletedAt = &now
+	r.reserve(key)
+
+	r.saved = append(r.saved, statements...)
+	r.actorID = actorID
+	return key, true, nil
+}
+
+func (r *submissionRepo) ReserveIdempotencyKey(_ context.Context, key models.IdempotencyKey) (models.IdempotencyKey, bool, error) {
+	if stored, ok := r.keys[key.Key]; ok {
+		return stored, false, nil
+	}
+	r.reserve(key)
+	return key, true, nil
+}
+
+func (r *submissionRepo) CompleteIdempotencyKey(_ context.Context, key string) error {
+	stored := r.keys[key]
+	now := time.Now()
+	stored.CompletedAt = &now
+	r.keys[key] = stored
+	return nil
+}
+
+func (r *submissionRepo) EnqueueOutbox(_ context.Context, msg models.OutboxMessage) error {
+	if r.outboxErr != nil {
+		return r.outboxErr
+	}
+	r.outbox = append(r.outbox, msg)
+	return nil
+}
+
+func (r *submissionRepo) reserve(key models.IdempotencyKey) {
+	if r.keys == nil {
+		r.keys = make(map[string]models.IdempotencyKey)
+	}
+	r.keys[key.Key] = key
+}
+
+// submissionBroker records published messages and fails while err is set.
+type submissionBroker struct {
+	MessageBroker
+	key   string
+	value []byte
+	sent  int
+	err   error
+}
+
+func (b *submissionBroker) Send(_ context.Context, key string, value []byte) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.key, b.value = key, value
+	b.sent++
+	return nil
+}
+
+func testStatement() models.Statement {
+	return models.Statement{
+		Source:      "portal",
+		District:    "Центральный",
+		Category:    "Мусор",
+		Subcategory: "Переполненные контейнеры",
+		Status:      models.StatusNew,
+		Description: "Контейнеры не вывозят неделю",
+	}
+}
+
+func TestCreateStatement_PublishesForWorker(t *testing.T) {
+	repo := &submissionRepo{}
+	broker := &submissionBroker{}
+	uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+
+	citizen := models.User{ID: 7, Role: models.RoleCitizen}
+	trackingID, _, err := uc.CreateStatement(context.Background(), citizen, "", []models.Statement{testStatement()})
+	if err != nil {
+		t.Fatalf("CreateStatement() error = %v", err)
+	}
+	if len(repo.saved) != 0 {
+		t.Fatalf("CreateStatement() stored %d statements synchronously", len(repo.saved))
+	}
+	if trackingID == "" || broker.key != trackingID {
+		t.Fatalf("message key = %q, tracking id = %q", broker.key, trackingID)
+	}
+
+	if err := uc.StoreSubmission(context.Background(), broker.value); err != nil {
+		t.Fatalf("StoreSubmission() error = %v", err)
+	}
+	if len(repo.saved) != 1 || repo.actorID != citizen.ID {
+		t.Fatalf("stored %d statements by actor %d, want 1 by %d", len(repo.saved), repo.actorID, citizen.ID)
+	}
+	if !repo.saved[0].AdminStatus {
+		t.Errorf("statement of a citizen is not queued for moderation")
+	}
+}
+
+func TestCreateStatement_InitialStatus(t *testing.T) {
+	tests := []struct {
+		role models.Role
+		want models.Status
+	}{
+		{role: models.RoleCitizen, want: models.StatusNew},
+		{role: models.RoleModerator, want: models.StatusNew},
+		{role: models.RoleExecutor, want: models.StatusResolved},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			broker := &submissionBroker{}
+			uc := NewStatementUseCase(&submissionRepo{}, nil, broker, nil, nil)
+
+			resolved := testStatement()
+			resolved.Status = models.StatusResolved
+			if _, _, err := uc.CreateStatement(context.Background(), models.User{ID: 7, Role: tt.role}, "", []models.Statement{resolved}); err != nil {
+				t.Fatal(err)
+			}
+			submission, err := decodeSubmission(broker.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := submission.Statements[0].Status; got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateStatement_CreatedAt(t *testing.T) {
+	backdated := time.Date(2023, 12, 9, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		role     models.Role
+		backdate bool
+	}{
+		{role: models.RoleCitizen},
+		{role: models.RoleExecutor},
+		{role: models.RoleAdmin, backdate: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			repo := &submissionRepo{}
+			broker := &submissionBroker{}
+			uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+
+			stmt := testStatement()
+			stmt.CreatedAt = backdated
+			if _, _, err := uc.CreateStatement(context.Background(), models.User{ID: 7, Role: tt.role}, "", []models.Statement{stmt}); err != nil {
+				t.Fatal(err)
+			}
+			if err := uc.StoreSubmission(context.Background(), broker.value); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := repo.saved[0].CreatedAt; got.Equal(backdated) != tt.backdate || got.IsZero() {
+				t.Errorf("created_at = %v, backdated %v", got, tt.backdate)
+			}
+		})
+	}
+}
+
+func TestStoreSubmission_LegacyArray(t *testing.T) {
+	repo := &submissionRepo{}
+	uc := NewStatementUseCase(repo, nil, nil, nil, nil)
+
+	value, err := json.Marshal([]models.Statement{testStatement(), testStatement()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.StoreSubmission(context.Background(), value); err != nil {
+		t.Fatalf("StoreSubmission() error = %v", err)
+	}
+	if len(repo.saved) != 2 || repo.actorID != 0 {
+		t.Errorf("stored %d statements by actor %d, want 2 without actor", len(repo.saved), repo.actorID)
+	}
+}
+
+func TestStoreSubmission_Invalid(t *testing.T) {
+	uc := NewStatementUseCase(&submissionRepo{}, nil, nil, nil, nil)
+
+	invalid := testStatement()
+	invalid.Description = ""
+	value, err := json.Marshal(models.Submission{TrackingID: "t", Statements: []models.Statement{invalid}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.StoreSubmission(context.Background(), value); !errors.Is(err, ErrInvalidSubmission) {
+		t.Errorf("StoreSubmission() error = %v, want ErrInvalidSubmission", err)
+	}
+	if err := uc.StoreSubmission(context.Background(), []byte("not json")); !errors.Is(err, ErrInvalidSubmission) {
+		t.Errorf("StoreSubmission() error = %v for undecodable value, want ErrInvalidSubmission", err)
+	}
+}
+
+func TestStoreSubmission_BareSubmission(t *testing.T) {
+	repo := &submissionRepo{}
+	uc := NewStatementUseCase(repo, nil, nil, nil, nil)
+
+	value, err := json.Marshal(models.Submission{TrackingID: "t", ActorID: 3, Statements: []models.Statement{testStatement()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.StoreSubmission(context.Background(), value); err != nil {
+		t.Fatalf("StoreSubmission() error = %v", err)
+	}
+	if len(repo.saved) != 1 || repo.actorID != 3 {
+		t.Errorf("stored %d statements by actor %d, want 1 by 3", len(repo.saved), repo.actorID)
+	}
+}
+
+func TestCreateStatement_IdempotencyKey(t *testing.T) {
+	repo := &submissionRepo{}
+	broker := &submissionBroker{}
+	uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+	ctx := context.Background()
+	citizen := models.User{ID: 7, Role: models.RoleCitizen}
+
+	first, replayed, err := uc.CreateStatement(ctx, citizen, "key-1", []models.Statement{testStatement()})
+	if err != nil || replayed {
+		t.Fatalf("CreateStatement() = %q, %v, %v", first, replayed, err)
+	}
+
+	second, replayed, err := uc.CreateStatement(ctx, citizen, "key-1", []models.Statement{testStatement()})
+	if err != nil {
+		t.Fatalf("CreateStatement() replay error = %v", err)
+	}
+	if second != first || !replayed {
+		t.Errorf("replay = %q, %v, want %q, true", second, replayed, first)
+	}
+	if broker.sent != 1 {
+		t.Errorf("published %d messages, want 1", broker.sent)
+	}
+
+	other, replayed, err := uc.CreateStatement(ctx, models.User{ID: 8, Role: models.RoleCitizen}, "key-1", []models.Statement{testStatement()})
+	if err != nil || replayed || other == first {
+		t.Errorf("key of another actor = %q, %v, %v, want a new submission", other, replayed, err)
+	}
+
+	changed := testStatement()
+	changed.Description = "Другое описание"
+	if _, _, err := uc.CreateStatement(ctx, citizen, "key-1", []models.Statement{changed}); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("CreateStatement() with other statements error = %v, want ErrIdempotencyKeyReused", err)
+	}
+
+	if _, _, err := uc.CreateStatement(ctx, citizen, "bad\nkey", []models.Statement{testStatement()}); !errors.Is(err, ErrInvalidIdempotencyKey) {
+		t.Errorf("CreateStatement() with bad key error = %v, want ErrInvalidIdempotencyKey", err)
+	}
+
+	if key := repo.keys["http:7:key-1"]; !key.ExpiresAt.After(time.Now().Add(idempotencyKeyTTL - time.Minute)) {
+		t.Errorf("key expires at %v, want in %v", key.ExpiresAt, idempotencyKeyTTL)
+	}
+}
+
+func TestCreateStatement_GuestIdempotencyKeyIgnored(t *testing.T) {
+	repo := &submissionRepo{}
+	broker := &submissionBroker{}
+	uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+	ctx := context.Background()
+
+	first, _, err := uc.CreateStatement(ctx, models.User{}, "key-1", []models.Statement{testStatement()})
+	if err != nil {
+		t.Fatalf("CreateStatement() error = %v", err)
+	}
+	second, replayed, err := uc.CreateStatement(ctx, models.User{}, "key-1", []models.Statement{testStatement()})
+	if err != nil || replayed || second == first {
+		t.Errorf("second guest submission = %q, %v, %v, want a new submission", second, replayed, err)
+	}
+	if len(repo.keys) != 0 || broker.sent != 2 {
+		t.Errorf("stored %d keys and published %d messages, want no keys and 2 messages", len(repo.keys), broker.sent)
+	}
+}
+
+func TestCreateStatement_OutboxWhileKafkaIsDown(t *testing.T) {
+	repo := &submissionRepo{}
+	broker := &submissionBroker{err: errors.New("kafka is down")}
+	uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+	ctx := context.Background()
+	citizen := models.User{ID: 7, Role: models.RoleCitizen}
+
+	trackingID, _, err := uc.CreateStatement(ctx, citizen, "key-1", []models.Statement{testStatement()})
+	if err != nil {
+		t.Fatalf("CreateStatement() error = %v", err)
+	}
+	if len(repo.outbox) != 1 || repo.outbox[0].Topic != models.OutboxSubmissions || repo.outbox[0].Key != trackingID {
+		t.Fatalf("outbox = %+v, want the submission keyed by %q", repo.outbox, trackingID)
+	}
+	if key := repo.keys["http:7:key-1"]; key.CompletedAt == nil {
+		t.Errorf("idempotency key is not completed after the submission is put into the outbox")
+	}
+
+	if err := uc.StoreSubmission(ctx, repo.outbox[0].Payload); err != nil {
+		t.Fatalf("StoreSubmission() error = %v", err)
+	}
+	if len(repo.saved) != 1 {
+		t.Errorf("stored %d statements, want 1", len(repo.saved))
+	}
+}
+
+func TestCreateStatement_IdempotencyKeyAfterFailedPublish(t *testing.T) {
+	repo := &submissionRepo{outboxErr: errors.New("postgres is down")}
+	broker := &submissionBroker{err: errors.New("kafka is down")}
+	uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+	ctx := context.Background()
+	citizen := models.User{ID: 7, Role: models.RoleCitizen}
+
+	if _, _, err := uc.CreateStatement(ctx, citizen, "key-1", []models.Statement{testStatement()}); err == nil {
+		t.Fatal("CreateStatement() error = nil with the broker and the outbox down")
+	}
+
+	broker.err = nil
+	trackingID, replayed, err := uc.CreateStatement(ctx, citizen, "key-1", []models.Statement{testStatement()})
+	if err != nil {
+		t.Fatalf("CreateStatement() retry error = %v", err)
+	}
+	if !replayed || broker.sent != 1 || broker.key != trackingID {
+		t.Errorf("retry = %q, replayed %v, sent %d with key %q, want the reserved tracking id published once",
+			trackingID, replayed, broker.sent, broker.key)
+	}
+}
+
+func TestStoreSubmission_Redelivery(t *testing.T) {
+	repo := &submissionRepo{}
+	broker := &submissionBroker{}
+	uc := NewStatementUseCase(repo, nil, broker, nil, nil)
+	ctx := context.Background()
+
+	trackingID, _, err := uc.CreateStatement(ctx, models.User{}, "", []models.Statement{testStatement()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := uc.StoreSubmission(ctx, broker.value); err != nil {
+			t.Fatalf("StoreSubmission() error = %v", err)
+		}
+	}
+	if len(repo.saved) != 1 {
+		t.Errorf("stored %d statements, want 1", len(repo.saved))
+	}
+	if _, ok := repo.keys["submission:"+trackingID]; !ok {
+		t.Errorf("keys = %v, want the tracking id", repo.keys)
+	}
+
+	legacy, err := json.Marshal([]models.Statement{testStatement()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := uc.StoreSubmission(ctx, legacy); err != nil {
+			t.Fatalf("StoreSubmission() legacy error = %v", err)
+		}
+	}
+	if len(repo.saved) != 2 {
+		t.Errorf("stored %d statements, want 2", len(repo.saved))
+	}
+}
@@ -4,37 +4,35 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 	"time"
 
+	"hack/internal/lib/llm"
 	"hack/internal/lib/validator"
 	"hack/internal/models"
-
-	mistral "github.com/ua1984/mistral"
 )
 
 type StatementRepository interface {
-	NewStatement(statements []models.Statement) error
+	NewStatement(ctx context.Context, key models.IdempotencyKey, statements []models.Statement, actorID int64) (models.IdempotencyKey, bool, error)
 	GetStatement(statementID int) (models.Statement, error)
-	DeleteStatement(statementID int) error
-	UpdateStatement(ctx context.Context, statements []models.Statement) error
-
-	GetAllNewStatements(ctx context.Context) ([]models.Statement, error)
-	GetRecomendatonsContext(ctx context.Context) ([]models.Statement, error)
-
-	GetCategoriesAnalitic(ctx context.Context, district string) (map[string]int, error)
+	DeleteStatement(statementID int, actorID int64) error
+	UpdateStatement(ctx context.Context, statements []models.Statement, actorID int64) error
+	ChangeStatus(ctx context.Context, transition models.StatusTransition) error
+	GetStatusTransitions(ctx context.Context, statementID int) ([]models.StatusTransition, error)
+	GetStatementHistory(ctx context.Context, statementID int) ([]models.StatementEvent, error)
+	ReserveIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (models.IdempotencyKey, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, key string) error
+	EnqueueOutbox(ctx context.Context, msg models.OutboxMessage) error
+
+	ListStatements(ctx context.Context, query models.StatementQuery) ([]models.Statement, int, error)
+	SearchStatements(ctx context.Context, query models.SearchQuery) ([]models.SearchHit, int, error)
+	GetRecomendatonsContext(ctx context.Context, filter models.StatementFilter) ([]models.Statement, error)
+
+	GetCategoriesAnalitic(ctx context.Context, filter models.StatementFilter) (map[string]int, error)
 	GetDistrictAnalitic(ctx context.Context) (map[string]int, error)
-	GetPeriodAnalitic(ctx context.Context) (map[string]int, error)
-}
-
-type CacheRepository interface {
-	GetStatement(ctx context.Context, statementUID int) ([]byte, error)
-	SetStatement(ctx context.Context, statementUID int, data []byte, ttl time.Duration) error
-	DeleteStatement(ctx context.Context, statementUID int) error
+	GetCrossTabAnalitic(ctx context.Context, filter models.StatementFilter) ([]models.CrossTabCell, error)
+	GetResolutionAnalitic(ctx context.Context, q models.ResolutionQuery, ageBounds []time.Duration) (models.ResolutionStats, []models.ResolutionStats, error)
+	GetPeriodAnalitic(ctx context.Context, filter models.StatementFilter, bucket models.Bucket) ([]models.PeriodPoint, error)
 }
 
 // MessageBroker defines methods for sending messages to Kafka.
@@ -43,226 +41,140 @@ type MessageBroker interface {
 	Close() error
 }
 
+// LLMClient generates chat completions, implemented by the clients of package llm.
+type LLMClient interface {
+	Complete(ctx context.Context, req llm.Request) (string, error)
+}
+
 // StatementUseCase contains dependencies and implements order-related use cases.
 type StatementUseCase struct {
 	statementRepo StatementRepository
-	cacheRepo     CacheRepository
+	cache         statementCache
 	messageBroker MessageBroker
+	llmClient     LLMClient
+	classifier    Classifier
 }
 
 // NewStatementUseCase creates a new instance of StatementUseCase with required dependencies.
-func NewStatementUseCase(statementRepo StatementRepository, cacheRepo CacheRepository, messageBroker MessageBroker) *StatementUseCase {
+// llmClient may be nil where recommendations are not served, e.g. in the ingestion worker,
+// and classifier where statements are not created or suggestions are disabled.
+func NewStatementUseCase(statementRepo StatementRepository, cacheRepo CacheRepository, messageBroker MessageBroker, llmClient LLMClient, classifier Classifier) *StatementUseCase {
 	return &StatementUseCase{
 		statementRepo: statementRepo,
-		cacheRepo:     cacheRepo,
+		cache:         newStatementCache(cacheRepo),
 		messageBroker: messageBroker,
+		llmClient:     llmClient,
+		classifier:    classifier,
 	}
 }
 
-// CreateOrder validates the order and sends it to Kafka for asynchronous processing.
-// It does not wait for persistence — that's handled by the consumer.
-func (uc *StatementUseCase) CreateStatement(ctx context.Context, statements []models.Statement) error {
-	const op = "usecase.CreateStatement"
+// UpdateStatement saves the statements. Every changed field is checked against the actor's role:
+// admin_status needs the moderate permission and any other field needs the edit permission.
+// The status cannot be changed here, use ChangeStatus instead.
+func (uc *StatementUseCase) UpdateStatement(ctx context.Context, actor models.User, statements []models.Statement) error {
+	const op = "usecase.UpdateStatement"
 
 	for _, statement := range statements {
 		if err := validator.ValidateStatement(&statement); err != nil {
-			return fmt.Errorf("%s: validator: %w", op, err)
+			return fmt.Errorf("%s: validator: %w: %w", op, ErrInvalidStatement, err)
 		}
-	}
-	statementJSON, err := json.Marshal(statements)
-	if err != nil {
-		return fmt.Errorf("%s: json marshal err: %w", op, err)
-	}
-	if len(statements) == 1 {
-		key := strconv.Itoa(statements[0].StatementUID)
-
-		if err := uc.messageBroker.Send(ctx, key, statementJSON); err != nil {
 
+		current, err := uc.statementRepo.GetStatement(statement.StatementUID)
+		if err != nil {
+			return fmt.Errorf("%s: statementRepo get statement: %w", op, err)
+		}
+		if current.Status != statement.Status {
+			return fmt.Errorf("%s: statement (id=%d): %w", op, statement.StatementUID, ErrStatusChangeNotAllowed)
+		}
+		for _, perm := range updatePermissions(current, statement) {
+			if !Can(actor.Role, perm) {
+				return fmt.Errorf("%s: %s (id=%d): %w", op, perm, statement.StatementUID, ErrForbidden)
+			}
 		}
 	}
 
-	if err := uc.statementRepo.NewStatement(statements); err != nil {
+	if err := uc.statementRepo.UpdateStatement(ctx, statements, actor.ID); err != nil {
 		return fmt.Errorf("%s: failed to save statement to repository: %w", op, err)
 	}
 
+	ids := make([]int, len(statements))
+	for i, statement := range statements {
+		ids[i] = statement.StatementUID
+	}
+	uc.cache.InvalidateStatements(ctx, ids...)
+
 	return nil
 }
 
-func (uc *StatementUseCase) UpdateStatement(ctx context.Context, statements []models.Statement) error {
-	const op = "usecase.UpdateStatement"
-
-	for _, statement := range statements {
-		if err := validator.ValidateStatement(&statement); err != nil {
-			return fmt.Errorf("%s: validator: %w", op, err)
-		}
-		uc.cacheRepo.DeleteStatement(ctx, statement.StatementUID)
+// updatePermissions returns the permissions required to turn current into updated.
+func updatePermissions(current, updated models.Statement) []Permission {
+	var perms []Permission
+	if current.AdminStatus != updated.AdminStatus {
+		perms = append(perms, PermStatementModerate)
 	}
-
-	if err := uc.statementRepo.UpdateStatement(ctx, statements); err != nil {
-		return fmt.Errorf("%s: failed to save statement to repository: %w", op, err)
+	if current.Source != updated.Source ||
+		current.District != updated.District ||
+		current.Category != updated.Category ||
+		current.Subcategory != updated.Subcategory ||
+		current.Description != updated.Description {
+		perms = append(perms, PermStatementEdit)
 	}
-
-	return nil
+	return perms
 }
 
 func (uc *StatementUseCase) GetStatement(ctx context.Context, statementUID int) (models.Statement, error) {
 	const op = "usecase.GetStatement"
 
-	cached, err := uc.cacheRepo.GetStatement(ctx, statementUID)
-	if err == nil && len(cached) > 0 {
-		var statement models.Statement
-		if jsonErr := json.Unmarshal(cached, &statement); jsonErr == nil {
-			return statement, nil
-		}
-		uc.cacheRepo.DeleteStatement(ctx, statementUID)
-	}
-
-	statement, err := uc.statementRepo.GetStatement(statementUID)
+	statement, err := uc.cache.Statement(ctx, statementUID, func(context.Context) (models.Statement, error) {
+		return uc.statementRepo.GetStatement(statementUID)
+	})
 	if err != nil {
 		return models.Statement{}, fmt.Errorf("%s: orderRepo get order: %w", op, err)
 	}
 
-	statementJSON, marshalErr := json.Marshal(statement)
-	if marshalErr == nil {
-		uc.cacheRepo.SetStatement(ctx, statementUID, statementJSON, 24*time.Hour)
-	}
-
 	return statement, nil
 }
 
-func (uc *StatementUseCase) DeleteStatement(ctx context.Context, statementUID int) error {
+func (uc *StatementUseCase) DeleteStatement(ctx context.Context, actor models.User, statementUID int) error {
 	const op = "usecase.DeleteStatement"
 
-	if err := uc.statementRepo.DeleteStatement(statementUID); err != nil {
+	if !Can(actor.Role, PermStatementDelete) {
+		return fmt.Errorf("%s: %s (id=%d): %w", op, PermStatementDelete, statementUID, ErrForbidden)
+	}
+
+	if err := uc.statementRepo.DeleteStatement(statementUID, actor.ID); err != nil {
 		return fmt.Errorf("%s: failed to delete statement (id=%d): %w", op, statementUID, err)
 	}
-	uc.cacheRepo.DeleteStatement(ctx, statementUID)
+	uc.cache.InvalidateStatements(ctx, statementUID)
 
 	return nil
 }
 
-func (uc *StatementUseCase) GetAllNewStatements(ctx context.Context) ([]models.Statement, error) {
-	const op = "usecase.GetStatement"
+// GetStatementHistory returns the audit trail of the statement, including deleted ones.
+// repository.ErrNotFound is returned for a statement that has neither events nor a row.
+func (uc *StatementUseCase) GetStatementHistory(ctx context.Context, statementUID int) ([]models.StatementEvent, error) {
+	const op = "usecase.GetStatementHistory"
 
-	statements, err := uc.statementRepo.GetAllNewStatements(ctx)
+	events, err := uc.statementRepo.GetStatementHistory(ctx, statementUID)
 	if err != nil {
-		return []models.Statement{}, fmt.Errorf("%s: orderRepo get order: %w", op, err)
+		return nil, fmt.Errorf("%s: statementRepo get history: %w", op, err)
 	}
-
-	return statements, nil
-}
-
-func (uc *StatementUseCase) GetCategoriesAnalitic(ctx context.Context, district string) (map[string]int, error) {
-	const op = "usecase.GetStatement"
-
-	analitic, err := uc.statementRepo.GetCategoriesAnalitic(ctx, district)
-	if err != nil {
-		return map[string]int{}, fmt.Errorf("%s: orderRepo get order: %w", op, err)
+	if len(events) == 0 {
+		if _, err := uc.statementRepo.GetStatement(statementUID); err != nil {
+			return nil, fmt.Errorf("%s: statementRepo get statement: %w", op, err)
+		}
 	}
 
-	return analitic, nil
+	return events, nil
 }
 
 func (uc *StatementUseCase) GetDistrictAnalitic(ctx context.Context) (map[string]int, error) {
 	const op = "usecase.GetDistrictAnalitic"
 
-	analitic, err := uc.statementRepo.GetDistrictAnalitic(ctx)
+	analitic, err := cached(ctx, uc.cache, analyticsKey("district", nil), analyticsTTL, uc.statementRepo.GetDistrictAnalitic, tagStatements)
 	if err != nil {
 		return map[string]int{}, fmt.Errorf("%s: orderRepo get order: %w", op, err)
 	}
 
 	return analitic, nil
 }
-
-func (uc *StatementUseCase) GetPeriodAnalitic(ctx context.Context) (map[string]int, error) {
-	const op = "usecase.GetPeriodAnalitic"
-
-	analitic, err := uc.statementRepo.GetPeriodAnalitic(ctx)
-	if err != nil {
-		return map[string]int{}, fmt.Errorf("%s: orderRepo get order: %w", op, err)
-	}
-
-	return analitic, nil
-}
-
-func GeneratePrompt(numRecommendations int, statements []models.Statement) string {
-	var contextBuilder strings.Builder
-	for _, stmt := range statements {
-		contextBuilder.WriteString(fmt.Sprintf(
-			"- Район: %s, Категория: %s/%s\n",
-			stmt.District,
-			stmt.Category,
-			stmt.Subcategory,
-		))
-	}
-
-	prompt := fmt.Sprintf(`Ты — городской аналитик. На основе предоставленных данных о проблемах города сформируй краткие практические рекомендации для жителей.
-
-Контекст (последние заявки от жителей):
-%s
-
-Инструкции:
-1. Проанализируй ВСЕ предоставленные заявки и выяви основные проблемы
-2. Сгенери ровно %d рекомендаций для жителей на основе текущей ситуации
-3. Каждая рекомендация должна быть:
-   - Практической и конкретной
-   - Не более 5 предложений
-   - Основана на реальных проблемах из контекста
-4. Формат вывода: каждая рекомендация отделяется символом "|"
-5. Не включай номера, заголовки или дополнительные комментарии
-
-Рекомендации:`, contextBuilder.String(), numRecommendations)
-
-	return prompt
-}
-
-func (uc *StatementUseCase) GetRecomendations(ctx context.Context, count int) ([]string, error) {
-	const op = "usecase.GetRecomendations"
-	client := mistral.NewClient(os.Getenv("AI_API_KEY"))
-
-	result := []string{}
-
-	cached, err := uc.cacheRepo.GetStatement(ctx, -1)
-	if err == nil && len(cached) > 0 {
-		if jsonErr := json.Unmarshal(cached, &result); jsonErr == nil {
-			return result, nil
-		}
-		uc.cacheRepo.DeleteStatement(ctx, -1)
-	}
-
-	statementsContext, err := uc.statementRepo.GetRecomendatonsContext(ctx)
-	if err != nil {
-		return []string{}, fmt.Errorf("%s: orderRepo get order: %w", op, err)
-	}
-
-	resp, err := client.CreateChatCompletion(ctx, &mistral.ChatCompletionRequest{
-		Model: "devstral-latest",
-		Messages: []mistral.ChatMessage{
-			{Role: mistral.RoleUser, Content: GeneratePrompt(count, statementsContext)},
-		},
-	})
-
-	if err != nil {
-		return []string{}, fmt.Errorf("%s: failed get recomendations: %w", op, err)
-	}
-
-	if len(resp.Choices) == 0 {
-		return []string{}, fmt.Errorf("%s: no choices in response", op)
-	}
-
-	responseText := resp.Choices[0].Message.Content.(string)
-
-	if responseText == "" {
-		return []string{}, nil
-	}
-
-	result = strings.Split(responseText, "|")
-	for i := range result {
-		result[i] = strings.TrimSpace(result[i])
-	}
-	if orderJSON, marshalErr := json.Marshal(result); marshalErr == nil {
-		uc.cacheRepo.SetStatement(ctx, -1, orderJSON, 1*time.Hour)
-	}
-
-	return result, nil
-}
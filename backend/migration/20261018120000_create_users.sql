@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE users (
+    id                  BIGSERIAL           PRIMARY KEY,
+    username            VARCHAR(100)        NOT NULL UNIQUE,
+    password_hash       VARCHAR(100)        NOT NULL,
+    created_at          TIMESTAMPTZ         NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE sessions (
+    token_hash          CHAR(64)            PRIMARY KEY,
+    user_id             BIGINT              NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at          TIMESTAMPTZ         NOT NULL DEFAULT NOW(),
+    expires_at          TIMESTAMPTZ         NOT NULL
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Существующие учетные записи получают роль citizen. Администратором остается только первая
+-- учетная запись — ее создает EnsureAdmin при первом запуске. Настроенный администратор
+-- (ADMIN_USERNAME) в любом случае получает роль при старте API.
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'citizen'
+    CHECK (role IN ('citizen', 'moderator', 'executor', 'admin'));
+
+UPDATE users SET role = 'admin' WHERE id = (SELECT MIN(id) FROM users);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE status_transitions (
+    id                  BIGSERIAL           PRIMARY KEY,
+    statement_id        BIGINT              NOT NULL REFERENCES statements(id) ON DELETE CASCADE,
+    from_status         VARCHAR(50)         NOT NULL,
+    to_status           VARCHAR(50)         NOT NULL,
+    actor_id            BIGINT              REFERENCES users(id) ON DELETE SET NULL,
+    reason              TEXT                NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ         NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_status_transitions_statement_id ON status_transitions(statement_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS status_transitions CASCADE;
+
+-- +goose StatementEnd
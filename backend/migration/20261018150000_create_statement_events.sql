@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- statement_id намеренно без внешнего ключа: события удаленных заявок должны сохраняться.
+CREATE TABLE statement_events (
+    id                  BIGSERIAL           PRIMARY KEY,
+    statement_id        BIGINT              NOT NULL,
+    event_type          VARCHAR(30)         NOT NULL,
+    actor_id            BIGINT              REFERENCES users(id) ON DELETE SET NULL,
+    changes             JSONB               NOT NULL DEFAULT '{}',
+    comment             TEXT                NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ         NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_statement_events_statement_id ON statement_events(statement_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS statement_events CASCADE;
+
+-- +goose StatementEnd
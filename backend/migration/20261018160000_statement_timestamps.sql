@@ -0,0 +1,68 @@
+-- +goose Up
+
+-- Даты без часового пояса (например, "2023-12-09" из выгрузки) считаются петербургскими.
+SET LOCAL timezone = 'Europe/Moscow';
+
+-- +goose StatementBegin
+CREATE FUNCTION try_cast_timestamptz(value TEXT) RETURNS TIMESTAMPTZ AS $$
+BEGIN
+    RETURN value::TIMESTAMPTZ;
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql STABLE;
+-- +goose StatementEnd
+
+-- Строки, которые не удалось разобрать, сохраняются в created_at_legacy, чтобы их можно было исправить вручную.
+ALTER TABLE statements ADD COLUMN created_at_legacy VARCHAR(50);
+
+UPDATE statements
+SET created_at_legacy = created_at
+WHERE try_cast_timestamptz(created_at) IS NULL;
+
+ALTER TABLE statements ALTER COLUMN created_at DROP DEFAULT;
+ALTER TABLE statements
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ
+    USING COALESCE(try_cast_timestamptz(created_at), NOW());
+ALTER TABLE statements ALTER COLUMN created_at SET DEFAULT NOW();
+
+DROP FUNCTION try_cast_timestamptz(TEXT);
+
+ALTER TABLE statements ADD COLUMN updated_at TIMESTAMPTZ;
+ALTER TABLE statements ADD COLUMN resolved_at TIMESTAMPTZ;
+
+UPDATE statements SET updated_at = created_at;
+
+ALTER TABLE statements ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE statements ALTER COLUMN updated_at SET DEFAULT NOW();
+
+-- Для заявок, решенных через переходы статусов, время решения известно точно. У остальных решенных
+-- (импортированных сразу со статусом «Решено») перехода нет, берется время последнего изменения.
+UPDATE statements s
+SET resolved_at = COALESCE(
+    (
+        SELECT MAX(t.created_at)
+        FROM status_transitions t
+        WHERE t.statement_id = s.id AND t.to_status = 'Решено'
+    ),
+    s.updated_at,
+    s.created_at
+)
+WHERE s.status = 'Решено';
+
+CREATE INDEX idx_statements_created_at ON statements(created_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_statements_created_at;
+
+ALTER TABLE statements DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE statements DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE statements ALTER COLUMN created_at DROP DEFAULT;
+ALTER TABLE statements
+    ALTER COLUMN created_at TYPE VARCHAR(50)
+    USING COALESCE(created_at_legacy, to_char(created_at, 'YYYY-MM-DD'));
+ALTER TABLE statements ALTER COLUMN created_at SET DEFAULT NOW();
+
+ALTER TABLE statements DROP COLUMN IF EXISTS created_at_legacy;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Индексы под keyset-пагинацию списка заявок: (поле сортировки, id).
+DROP INDEX IF EXISTS idx_statements_created_at;
+CREATE INDEX idx_statements_created_at_id ON statements(created_at, id);
+CREATE INDEX idx_statements_updated_at_id ON statements(updated_at, id);
+CREATE INDEX idx_statements_admin_status_created_at ON statements(admin_status, created_at, id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_statements_admin_status_created_at;
+DROP INDEX IF EXISTS idx_statements_updated_at_id;
+DROP INDEX IF EXISTS idx_statements_created_at_id;
+CREATE INDEX idx_statements_created_at ON statements(created_at);
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE INDEX idx_status_transitions_resolved ON status_transitions(statement_id, created_at)
+    WHERE to_status = 'Решено';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_status_transitions_resolved;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Сообщения пишутся в одной транзакции с изменением заявки и удаляются relay'ем после публикации в Kafka.
+CREATE TABLE outbox (
+    id                  BIGSERIAL           PRIMARY KEY,
+    message_key         VARCHAR(100)        NOT NULL,
+    payload             JSONB               NOT NULL,
+    created_at          TIMESTAMPTZ         NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS outbox CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Ключи идемпотентности обработанных запросов: заголовок Idempotency-Key у POST /api/statement
+-- и ключи, выведенные worker'ом из сообщений Kafka. result — исходный ответ, который возвращается при повторе.
+CREATE TABLE idempotency_keys (
+    key                 VARCHAR(400)        PRIMARY KEY,
+    request_hash        VARCHAR(64)         NOT NULL,
+    result              JSONB               NOT NULL,
+    created_at          TIMESTAMPTZ         NOT NULL DEFAULT NOW(),
+    completed_at        TIMESTAMPTZ
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS idempotency_keys CASCADE;
+
+-- +goose StatementEnd
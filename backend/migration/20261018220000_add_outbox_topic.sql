@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Кроме событий заявок в outbox попадают заявки, которые не удалось сразу отправить в Kafka.
+-- Каждый topic публикует свой relay.
+ALTER TABLE outbox ADD COLUMN topic VARCHAR(50) NOT NULL DEFAULT 'events';
+
+CREATE INDEX outbox_topic_id_idx ON outbox (topic, id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS outbox_topic_id_idx;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS topic;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Категория и подкатегория, которые классификатор предлагает модератору вместо выбранных жителем.
+-- Заполняются для заявок, ушедших на модерацию; NULL, если предложения нет.
+ALTER TABLE statements
+    ADD COLUMN suggested_category    VARCHAR(80),
+    ADD COLUMN suggested_subcategory VARCHAR(150),
+    ADD COLUMN suggestion_confidence DOUBLE PRECISION,
+    ADD COLUMN suggestion_source     VARCHAR(20);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE statements
+    DROP COLUMN IF EXISTS suggestion_source,
+    DROP COLUMN IF EXISTS suggestion_confidence,
+    DROP COLUMN IF EXISTS suggested_subcategory,
+    DROP COLUMN IF EXISTS suggested_category;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Ключи идемпотентности хранятся ограниченное время, после expires_at их удаляет периодическая очистка.
+-- Для уже сохраненных ключей срок отсчитывается от created_at.
+ALTER TABLE idempotency_keys ADD COLUMN expires_at TIMESTAMPTZ;
+
+UPDATE idempotency_keys SET expires_at = created_at + INTERVAL '7 days';
+
+ALTER TABLE idempotency_keys ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idempotency_keys_expires_at_idx;
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS expires_at;
+
+-- +goose StatementEnd
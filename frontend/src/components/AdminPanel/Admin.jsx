@@ -8,14 +8,15 @@ export default function Admin() {
 
     const [tasks, setTasks] = useState([])
     useEffect(() => {
-        fetch('api/statement')
+        fetch('api/statement?limit=200')
             .then(r => {
                 if (r.status > 200) {
                     setShowReg(true)
+                    throw new Error(`HTTP ${r.status}`)
                 }
+                return r.json()
             })
-            .then(r => r.json())
-            .then(setTasks)
+            .then(page => setTasks(page.items))
             .catch(console.error)
     }, [])
     async function handleAccept(task) {
@@ -14,7 +14,7 @@ export default function App() {
     const [themeIsBlack, setThemeIsBlack] = useState(false)
     const [requestOpen, setRequestOpen] = useState(false)
 
-    const [selectedDistrict, setSelectedDistrict] = useState('1');
+    const [selectedDistrict, setSelectedDistrict] = useState('');
     const [categoriesData, setCategoriesData] = useState({});
     const [districtData, setDistrictData] = useState({})
     const [periodData, setPeriodData] = useState({})
@@ -27,24 +27,25 @@ export default function App() {
             .then(r => r.json())
             .then(setDistrictData)
             .catch(console.error)
-        fetch('api/analitic/period')
+        fetch('api/analitic/period?bucket=month')
             .then(r => r.json())
-            .then(setPeriodData)
+            .then(series => setPeriodData(
+                Object.fromEntries(series.points.map(p => [p.label, p.count]))
+            ))
             .catch(console.error)
-        fetch(`api/analitic/categories/1`)
+    }, [])
+    useEffect(() => {
+        const params = new URLSearchParams();
+        if (selectedDistrict) params.append('district', selectedDistrict);
+        fetch(`api/analitic/categories?${params}`)
             .then(res => res.json())
             .then(data => setCategoriesData(data))
             .catch(console.error);
-        fetch(`api/analitic/recs?c=2`)
+        params.append('c', 2);
+        fetch(`api/analitic/recs?${params}`)
             .then(res => res.json())
             .then(rec => setRec(rec))
             .catch(console.error);
-    }, [])
-    useEffect(() => {
-        fetch(`api/analitic/categories/${selectedDistrict}`)
-            .then(res => res.json())
-            .then(data => setCategoriesData(data))
-            .catch(console.error);
     }, [selectedDistrict]);
 
     useEffect(() => {
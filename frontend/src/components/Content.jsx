@@ -19,7 +19,7 @@ export default function Content({ categoriesData, districtData, periodData, sele
                             value={selectedDistrict}
                             onChange={e => setSelectedDistrict(e.target.value)}
                         >
-                            <option value="1">Все районы</option>
+                            <option value="">Все районы</option>
                             <option value="Адмиралтейский">Адмиралтейский</option>
                             <option value="Василеостровский">Василеостровский</option>
                             <option value="Выборгский">Выборгский</option>
@@ -46,14 +46,14 @@ export default function Content({ categoriesData, districtData, periodData, sele
                 </div>
                 <div className='charts__chart card-c'>
                     <div className="chart-area recom-area">
-                        <h1 className="sidebar__title">Рекомендация</h1>
-                        <p className="sidebar__text">{rec[0]}</p>
+                        <h1 className="sidebar__title">{rec[0]?.title || 'Рекомендация'}</h1>
+                        <p className="sidebar__text">{rec[0]?.body}</p>
                     </div>
                 </div>
                 <div className='charts__chart card-d'>
                     <div className="chart-area recom-area">
-                        <h1 className="sidebar__title">Рекомендация</h1>
-                        <p className="sidebar__text">{rec[1]}</p>
+                        <h1 className="sidebar__title">{rec[1]?.title || 'Рекомендация'}</h1>
+                        <p className="sidebar__text">{rec[1]?.body}</p>
                     </div>
                 </div>
             </div>